@@ -0,0 +1,99 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"secret", "secret"},
+		{"with space", `'with space'`},
+		{"it's", `'it\'s'`},
+		{`back\slash`, `'back\\slash'`},
+		{`a b'c\d`, `'a b\'c\\d'`},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := quote(tt.value); got != tt.want {
+			t.Errorf("quote(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDSN(t *testing.T) {
+	cfg := Config{Host: "db.internal", Port: "5432", User: "huntline", Password: `p@ss word'\`, Name: "huntline"}
+	want := `user=huntline password='p@ss word\'\\' dbname=huntline host=db.internal port=5432 sslmode=disable TimeZone=America/Los_Angeles`
+	if got := cfg.dsn(); got != want {
+		t.Errorf("dsn() = %s\nwant    %s", got, want)
+	}
+
+	cfg = Config{Host: "localhost", SSLMode: "require", TimeZone: "UTC"}
+	want = "host=localhost sslmode=require TimeZone=UTC"
+	if got := cfg.dsn(); got != want {
+		t.Errorf("dsn() = %s, want %s", got, want)
+	}
+
+	// A complete connection string wins over the discrete fields
+	cfg = Config{DSN: "postgres://u:p@host/db?sslmode=require", Host: "ignored"}
+	if got := cfg.dsn(); got != cfg.DSN {
+		t.Errorf("dsn() = %s, want DATABASE_URL unchanged", got)
+	}
+}
+
+// clearEnv unsets the variables ConfigFromEnv reads for the duration of a test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"DB_DRIVER", "SQLITE_PATH", "DATABASE_URL", "PG_HOST", "PG_PORT", "PG_USER", "PG_PASS", "PG_NAME",
+		"PG_SSLMODE", "PG_TIMEZONE", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_LIFETIME",
+		"DB_CONN_MAX_IDLE_TIME", "DB_CONNECT_RETRIES", "DB_RETRY_DELAY",
+	} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("PG_HOST", "db.internal")
+	t.Setenv("DB_MAX_OPEN_CONNS", "20")
+	t.Setenv("DB_CONN_MAX_LIFETIME", "30m")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Driver != DriverPostgres || cfg.Host != "db.internal" {
+		t.Errorf("driver %q host %q, want postgres on db.internal", cfg.Driver, cfg.Host)
+	}
+	if cfg.MaxOpenConns != 20 || cfg.ConnMaxLifetime != 30*time.Minute {
+		t.Errorf("pool %d conns for %v, want 20 for 30m", cfg.MaxOpenConns, cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnectRetries != 5 || cfg.RetryDelay != time.Second {
+		t.Errorf("retries %d every %v, want the defaults 5 and 1s", cfg.ConnectRetries, cfg.RetryDelay)
+	}
+}
+
+func TestConfigFromEnvErrors(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DB_DRIVER", "mysql")
+	t.Setenv("DB_MAX_OPEN_CONNS", "-1")
+	t.Setenv("DB_MAX_IDLE_CONNS", "many")
+	t.Setenv("DB_CONN_MAX_IDLE_TIME", "-5m")
+	t.Setenv("DB_RETRY_DELAY", "soon")
+
+	_, err := ConfigFromEnv()
+	if err == nil {
+		t.Fatal("ConfigFromEnv accepted invalid variables")
+	}
+	// Every invalid variable is reported at once
+	for _, name := range []string{"DB_DRIVER", "DB_MAX_OPEN_CONNS", "DB_MAX_IDLE_CONNS", "DB_CONN_MAX_IDLE_TIME", "DB_RETRY_DELAY"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error does not mention %s: %v", name, err)
+		}
+	}
+}
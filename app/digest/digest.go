@@ -0,0 +1,91 @@
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+	"gorm.io/gorm"
+)
+
+// DefaultLimit is the number of products per platform in a digest.
+const DefaultLimit = 10
+
+// PlatformTop is the top list of one platform in a digest.
+type PlatformTop struct {
+	Platform string
+	Products []model.Product
+}
+
+// Digest is the top products per platform of the day or week before it is sent.
+type Digest struct {
+	Frequency string
+	From      time.Time
+	To        time.Time
+	Platforms []PlatformTop
+}
+
+// Period describes the dates the digest covers, e.g. "Oct 18, 2026" or "Oct 12 – Oct 18, 2026".
+func (d Digest) Period() string {
+	if d.From.Equal(d.To) {
+		return d.To.Format("Jan 2, 2006")
+	}
+	return d.From.Format("Jan 2") + " – " + d.To.Format("Jan 2, 2006")
+}
+
+// Subject is the email subject of the digest.
+func (d Digest) Subject(siteName string) string {
+	if d.Frequency == model.FrequencyWeekly {
+		return fmt.Sprintf("%s weekly: top products of %s", siteName, d.Period())
+	}
+	return fmt.Sprintf("%s daily: top products of %s", siteName, d.Period())
+}
+
+// Empty reports whether the digest has no products to send.
+func (d Digest) Empty() bool {
+	return len(d.Platforms) == 0
+}
+
+// Build collects the digest of a frequency as of now: yesterday's rankings for the daily digest,
+// and the best rank of each product over the 7 days up to yesterday for the weekly digest.
+func Build(db *gorm.DB, frequency string, now time.Time, limit int) (Digest, error) {
+	loc := types.SanFranciscoLocation()
+	now = now.In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, -1)
+	from := to
+	switch frequency {
+	case model.FrequencyDaily:
+	case model.FrequencyWeekly:
+		from = to.AddDate(0, 0, -6)
+	default:
+		return Digest{}, fmt.Errorf("unknown digest frequency %q, expected daily or weekly", frequency)
+	}
+
+	var products []model.Product
+	err := db.Scopes(model.Ranked).
+		Where("date >= ? AND date <= ?", from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Order("platform ASC, rank ASC, date ASC").
+		Find(&products).Error
+	if err != nil {
+		return Digest{}, err
+	}
+
+	d := Digest{Frequency: frequency, From: from, To: to}
+	var current *PlatformTop
+	seen := make(map[string]bool)
+	for _, product := range products {
+		if current == nil || current.Platform != product.Platform {
+			d.Platforms = append(d.Platforms, PlatformTop{Platform: product.Platform})
+			current = &d.Platforms[len(d.Platforms)-1]
+			seen = make(map[string]bool)
+		}
+		// Over a week a product may rank on several days; keep its best rank
+		if seen[product.Name] || len(current.Products) >= limit {
+			continue
+		}
+		seen[product.Name] = true
+		current.Products = append(current.Products, product)
+	}
+	return d, nil
+}
@@ -0,0 +1,101 @@
+package digest
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/dariubs/huntline/app/mail"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+)
+
+// Templates are the email templates of view/email: digest and confirm, each as .html and .txt.
+type Templates struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// LoadTemplates parses the email templates in dir.
+func LoadTemplates(dir string) (*Templates, error) {
+	html, err := htmltemplate.ParseGlob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, err
+	}
+	text, err := texttemplate.ParseGlob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	return &Templates{html: html, text: text}, nil
+}
+
+// render executes the HTML and text templates of name with data.
+func (t *Templates) render(name string, data interface{}) (html, text string, err error) {
+	var hb, tb bytes.Buffer
+	err = t.html.ExecuteTemplate(&hb, name+".html", data)
+	if err != nil {
+		return "", "", err
+	}
+	err = t.text.ExecuteTemplate(&tb, name+".txt", data)
+	if err != nil {
+		return "", "", err
+	}
+	return hb.String(), tb.String(), nil
+}
+
+// siteName returns the configured site name, HuntLine by default.
+func siteName(gd types.General) string {
+	if gd.Name == "" {
+		return "HuntLine"
+	}
+	return gd.Name
+}
+
+// siteURL returns the absolute URL of path on the site.
+func siteURL(gd types.General, path string) string {
+	return strings.TrimRight(gd.URL, "/") + path
+}
+
+// DigestMessage renders the digest email of a subscriber, with one-click unsubscribe headers.
+func (t *Templates) DigestMessage(d Digest, subscriber model.Subscriber, gd types.General) (mail.Message, error) {
+	unsubscribeURL := siteURL(gd, "/unsubscribe?token="+subscriber.UnsubscribeToken)
+	html, text, err := t.render("digest", map[string]interface{}{
+		"siteName":       siteName(gd),
+		"digest":         d,
+		"siteURL":        strings.TrimRight(gd.URL, "/"),
+		"unsubscribeURL": unsubscribeURL,
+	})
+	if err != nil {
+		return mail.Message{}, err
+	}
+	return mail.Message{
+		To:      []string{subscriber.Email},
+		Subject: d.Subject(siteName(gd)),
+		Text:    text,
+		HTML:    html,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+// ConfirmMessage renders the double opt-in email asking a subscriber to confirm the address.
+func (t *Templates) ConfirmMessage(subscriber model.Subscriber, gd types.General) (mail.Message, error) {
+	html, text, err := t.render("confirm", map[string]interface{}{
+		"siteName":   siteName(gd),
+		"frequency":  subscriber.Frequency,
+		"confirmURL": siteURL(gd, "/subscribe/confirm?token="+subscriber.ConfirmToken),
+	})
+	if err != nil {
+		return mail.Message{}, err
+	}
+	return mail.Message{
+		To:      []string{subscriber.Email},
+		Subject: "Confirm your " + siteName(gd) + " subscription",
+		Text:    text,
+		HTML:    html,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// Package enrich fetches product homepages and extracts what they say about themselves from their
+// OpenGraph, Twitter card and HTML head tags.
+package enrich
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/dariubs/huntline/app/linkcheck"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/publicnet"
+	"github.com/dariubs/huntline/app/targeturl"
+	"golang.org/x/net/html/charset"
+)
+
+// maxBody is the number of bytes of a homepage read to find its head.
+const maxBody = 1 << 20
+
+// defaultClient follows redirects like http.DefaultClient but gives up on a site after 15 seconds.
+// It only connects to public addresses and checks every redirect, see publicnet.
+var defaultClient = publicnet.Client(15 * time.Second)
+
+// Fetcher fetches the metadata of product websites.
+type Fetcher struct {
+	// Client sends the requests; nil uses a client with a 15 second timeout that only connects to
+	// public addresses
+	Client *http.Client
+	// Concurrency is the number of hosts fetched at the same time (default linkcheck.DefaultConcurrency)
+	Concurrency int
+	// HostDelay is the pause between two requests to the same host (default linkcheck.DefaultHostDelay)
+	HostDelay time.Duration
+}
+
+// Fetch requests a homepage and parses its metadata. A failed fetch has its Error set.
+func (f Fetcher) Fetch(ctx context.Context, link string) model.SiteMetadata {
+	md := model.SiteMetadata{URL: link, FetchedAt: time.Now()}
+	err := f.fetch(ctx, &md)
+	if err != nil {
+		md.Error = err.Error()
+	}
+	return md
+}
+
+// fetch requests the homepage of md.URL and fills in md.
+func (f Fetcher) fetch(ctx context.Context, md *model.SiteMetadata) error {
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, md.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", targeturl.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	md.StatusCode = resp.StatusCode
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return fmt.Errorf("not an HTML page: %s", mediaType)
+	}
+
+	// Pages in another encoding than UTF-8, declared in the header or a meta tag, are decoded
+	body, err := charset.NewReader(io.LimitReader(resp.Body, maxBody), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("decoding page: %w", err)
+	}
+	parsed := Parse(body, resp.Request.URL)
+	md.Title = parsed.Title
+	md.Description = parsed.Description
+	md.Image = parsed.Image
+	md.Favicon = parsed.Favicon
+	return nil
+}
+
+// FetchAll fetches the metadata of the websites and passes each result to record as soon as it is
+// done, visiting the hosts politely (see linkcheck.EachHost). record is called from several
+// goroutines. It returns early when ctx is canceled.
+func (f Fetcher) FetchAll(ctx context.Context, links []string, record func(model.SiteMetadata)) {
+	linkcheck.EachHost(ctx, links, f.Concurrency, f.HostDelay, func(link string) {
+		record(f.Fetch(ctx, link))
+	})
+}
@@ -0,0 +1,168 @@
+package enrich
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Lengths the extracted fields are cut to.
+const (
+	maxTitle       = 255
+	maxDescription = 1000
+)
+
+// Metadata is what a website says about itself in its HTML head.
+type Metadata struct {
+	Title       string
+	Description string
+	// Image and Favicon are absolute URLs
+	Image   string
+	Favicon string
+}
+
+// Parse extracts the metadata of an HTML page. OpenGraph tags are preferred over Twitter card
+// tags, which are preferred over the plain title and description; relative image and icon URLs
+// are resolved against base. Without an icon link the favicon is base's /favicon.ico.
+func Parse(r io.Reader, base *url.URL) Metadata {
+	var (
+		meta     = make(map[string]string)
+		title    string
+		icon     string
+		iconRank int
+		inTitle  bool
+	)
+
+	z := html.NewTokenizer(r)
+loop:
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			break loop
+		case html.TextToken:
+			if inTitle && title == "" {
+				title = string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				// Everything we look for is in the head
+				break loop
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "title":
+				inTitle = tt == html.StartTagToken
+			case "body":
+				break loop
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+				attrs := attributes(z)
+				key := attrs["property"]
+				if key == "" {
+					key = attrs["name"]
+				}
+				key = strings.ToLower(strings.TrimSpace(key))
+				if key != "" && meta[key] == "" {
+					meta[key] = attrs["content"]
+				}
+			case "link":
+				if !hasAttr {
+					continue
+				}
+				attrs := attributes(z)
+				rank := iconRel(attrs["rel"])
+				if rank > iconRank && attrs["href"] != "" {
+					icon, iconRank = attrs["href"], rank
+				}
+			}
+		}
+	}
+
+	md := Metadata{
+		Title:       clean(first(meta["og:title"], meta["twitter:title"], title), maxTitle),
+		Description: clean(first(meta["og:description"], meta["twitter:description"], meta["description"]), maxDescription),
+		Image:       resolve(base, first(meta["og:image:secure_url"], meta["og:image"], meta["og:image:url"], meta["twitter:image"], meta["twitter:image:src"])),
+		Favicon:     resolve(base, icon),
+	}
+	if md.Favicon == "" {
+		md.Favicon = resolve(base, "/favicon.ico")
+	}
+	return md
+}
+
+// attributes returns the attributes of the current tag, with lowercase keys.
+func attributes(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, value, more := z.TagAttr()
+		attrs[strings.ToLower(string(key))] = string(value)
+		if !more {
+			return attrs
+		}
+	}
+}
+
+// iconRel ranks the icon link relations: 0 is not an icon, higher is preferred.
+func iconRel(rel string) int {
+	best := 0
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		switch r {
+		case "icon":
+			best = max(best, 3)
+		case "apple-touch-icon", "apple-touch-icon-precomposed":
+			best = max(best, 2)
+		case "mask-icon":
+			best = max(best, 1)
+		}
+	}
+	return best
+}
+
+// first returns the first non-blank value.
+func first(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// clean collapses the whitespace of a value and cuts it to limit runes.
+func clean(value string, limit int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	runes := []rune(value)
+	if len(runes) > limit {
+		value = strings.TrimSpace(string(runes[:limit-1])) + "…"
+	}
+	return value
+}
+
+// resolve returns ref as an absolute http(s) URL relative to base, or "" if it is not one.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return ""
+	}
+	return u.String()
+}
@@ -0,0 +1,145 @@
+package enrich
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func parseFile(t *testing.T, name, base string) Metadata {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	u, err := url.Parse(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Parse(f, u)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file string
+		base string
+		want Metadata
+	}{
+		{
+			// OpenGraph wins over Twitter and plain tags, tags after the head are ignored
+			file: "opengraph.html",
+			base: "https://acme.example.com/app/",
+			want: Metadata{
+				Title:       "Acme",
+				Description: "The fastest way to ship",
+				Image:       "https://acme.example.com/images/og.png",
+				Favicon:     "https://acme.example.com/app/static/favicon.png",
+			},
+		},
+		{
+			// Twitter wins over plain tags, an apple-touch-icon over a mask-icon
+			file: "twitter.html",
+			base: "https://widget.example.com/",
+			want: Metadata{
+				Title:       "Widget for teams",
+				Description: "Twitter description",
+				Image:       "https://cdn.example.com/card.png",
+				Favicon:     "https://static.example.com/touch.png",
+			},
+		},
+		{
+			// Plain title and description, no usable image and no icon link
+			file: "plain.html",
+			base: "https://plain.example.com/about",
+			want: Metadata{
+				Title:       "Plain page",
+				Description: "Only the basics",
+				Favicon:     "https://plain.example.com/favicon.ico",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got := parseFile(t, tt.file, tt.base)
+			if got != tt.want {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTruncates(t *testing.T) {
+	page := "<html><head><title>" + strings.Repeat("é", maxTitle+10) + "</title>" +
+		`<meta name="description" content="` + strings.Repeat("word ", maxDescription) + `">` +
+		"</head></html>"
+	got := Parse(strings.NewReader(page), nil)
+
+	if n := utf8.RuneCountInString(got.Title); n != maxTitle {
+		t.Errorf("title has %d runes, want %d", n, maxTitle)
+	}
+	if !strings.HasSuffix(got.Title, "…") {
+		t.Errorf("cut title %q does not end with an ellipsis", got.Title)
+	}
+	if n := utf8.RuneCountInString(got.Description); n > maxDescription {
+		t.Errorf("description has %d runes, want at most %d", n, maxDescription)
+	}
+	if !strings.HasSuffix(got.Description, "word…") {
+		t.Errorf("cut description ends with %q", got.Description[len(got.Description)-20:])
+	}
+	// Without a base there is nothing to resolve the favicon against
+	if got.Favicon != "" {
+		t.Errorf("favicon = %q without a base", got.Favicon)
+	}
+}
+
+func TestFetchDecodesCharset(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("testdata", "windows-1252.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, contentType := range []string{
+		// Declared by the meta tag only
+		"text/html",
+		// Declared by the header
+		"text/html; charset=windows-1252",
+	} {
+		t.Run(contentType, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", contentType)
+				w.Write(page)
+			}))
+			defer server.Close()
+
+			md := Fetcher{Client: server.Client()}.Fetch(context.Background(), server.URL+"/")
+			if md.Error != "" {
+				t.Fatalf("Fetch failed: %s", md.Error)
+			}
+			if md.Title != "Café – menú" {
+				t.Errorf("title = %q, want %q", md.Title, "Café – menú")
+			}
+		})
+	}
+}
+
+func TestFetchRefusesLocalAddresses(t *testing.T) {
+	requested := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+	}))
+	defer server.Close()
+
+	md := Fetcher{}.Fetch(context.Background(), server.URL+"/")
+	if md.Error == "" {
+		t.Error("Fetch from a loopback address succeeded")
+	}
+	if requested {
+		t.Error("loopback server got a request")
+	}
+}
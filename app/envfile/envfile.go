@@ -0,0 +1,19 @@
+// Package envfile loads the .env file the binaries read their configuration from.
+package envfile
+
+import (
+	"errors"
+	"io/fs"
+
+	"github.com/joho/godotenv"
+)
+
+// Load sets the variables of the .env file in the working directory that are not set yet. The
+// file is optional: the variables may come from the environment instead, e.g. in a container.
+func Load() error {
+	err := godotenv.Load()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package freshness
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/platform"
+	"github.com/dariubs/huntline/app/types"
+	"gorm.io/gorm"
+)
+
+// Expectation is the schedule a platform's rankings are expected on.
+type Expectation struct {
+	Platform string
+	// Depth is the number of products of a complete day
+	Depth int
+	// Due is how long after midnight (Pacific Time) a day's first rankings must be stored.
+	// From then on the previous day must also be complete.
+	Due time.Duration
+}
+
+// Kinds of problem found by Check.
+const (
+	// Missing means no ranked products are stored for the date
+	Missing = "missing"
+	// Short means fewer ranked products than the platform's depth are stored for the date
+	Short = "short"
+)
+
+// Problem is a date whose rankings are missing or short.
+type Problem struct {
+	Platform string `json:"platform"`
+	Date     string `json:"date"`
+	Kind     string `json:"kind"`
+	Count    int64  `json:"count"`
+	Expected int    `json:"expected"`
+}
+
+func (p Problem) String() string {
+	if p.Kind == Missing {
+		return fmt.Sprintf("%s %s: missing", p.Platform, p.Date)
+	}
+	return fmt.Sprintf("%s %s: short (%d of %d)", p.Platform, p.Date, p.Count, p.Expected)
+}
+
+// key identifies a problem across checks, regardless of the stored count.
+func (p Problem) key() string {
+	return p.Platform + "/" + p.Date + "/" + p.Kind
+}
+
+// Check compares the stored rankings of today and yesterday with each platform's expectation at now.
+// Before today's rankings are due, yesterday only needs some rankings; afterwards today needs some
+// rankings and yesterday a full list.
+func Check(db *gorm.DB, expectations []Expectation, now time.Time) ([]Problem, error) {
+	now = now.In(types.SanFranciscoLocation())
+	todayStr := now.Format("2006-01-02")
+	yesterdayStr := now.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var problems []Problem
+	for _, e := range expectations {
+		counts, err := model.CountByDate(db, e.Platform, yesterdayStr, todayStr)
+		if err != nil {
+			return nil, fmt.Errorf("counting rankings of %s: %w", e.Platform, err)
+		}
+		problems = append(problems, evaluate(e, counts, now)...)
+	}
+	return problems, nil
+}
+
+// evaluate returns the problems of a platform at now, given the number of ranked products stored
+// per date.
+func evaluate(e Expectation, counts map[string]int64, now time.Time) []Problem {
+	loc := types.SanFranciscoLocation()
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	todayStr := today.Format("2006-01-02")
+	yesterdayStr := today.AddDate(0, 0, -1).Format("2006-01-02")
+
+	var problems []Problem
+	todayDue := !now.Before(today.Add(e.Due))
+	yesterdayMin := int64(1)
+	if todayDue {
+		yesterdayMin = int64(e.Depth)
+	}
+	if p, ok := checkDate(e, yesterdayStr, counts[yesterdayStr], yesterdayMin); !ok {
+		problems = append(problems, p)
+	}
+	if todayDue {
+		if p, ok := checkDate(e, todayStr, counts[todayStr], 1); !ok {
+			problems = append(problems, p)
+		}
+	}
+	return problems
+}
+
+// checkDate returns the problem of a date with count stored products when it needs at least min.
+func checkDate(e Expectation, date string, count, min int64) (Problem, bool) {
+	if count >= min {
+		return Problem{}, true
+	}
+	p := Problem{Platform: e.Platform, Date: date, Kind: Short, Count: count, Expected: e.Depth}
+	if count == 0 {
+		p.Kind = Missing
+	}
+	return p, false
+}
+
+// ExpectationsFromEnv reads the platforms to watch from FRESHNESS_PLATFORMS (comma separated,
+// default producthunt) and the time their rankings are due from FRESHNESS_DUE_<PLATFORM>, e.g.
+// FRESHNESS_DUE_PRODUCTHUNT, falling back to FRESHNESS_DUE (default 2h).
+// Each platform's depth is its configured top-N depth.
+func ExpectationsFromEnv() ([]Expectation, error) {
+	due, err := dueFromEnv("FRESHNESS_DUE", 2*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	names := "producthunt"
+	if value := os.Getenv("FRESHNESS_PLATFORMS"); value != "" {
+		names = value
+	}
+
+	var expectations []Expectation
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		depth, err := platform.Depth(name)
+		if err != nil {
+			return nil, err
+		}
+		platformDue, err := dueFromEnv("FRESHNESS_DUE_"+strings.ToUpper(name), due)
+		if err != nil {
+			return nil, err
+		}
+		expectations = append(expectations, Expectation{Platform: name, Depth: depth, Due: platformDue})
+	}
+	return expectations, nil
+}
+
+// dueFromEnv reads a due time from the environment variable key, or returns fallback when it is unset.
+func dueFromEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 || d >= 24*time.Hour {
+		return 0, fmt.Errorf("invalid %s %q: expected a duration between 0 and 24h", key, value)
+	}
+	return d, nil
+}
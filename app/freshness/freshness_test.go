@@ -0,0 +1,73 @@
+package freshness
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dariubs/huntline/app/types"
+)
+
+func TestEvaluate(t *testing.T) {
+	e := Expectation{Platform: "producthunt", Depth: 10, Due: 2 * time.Hour}
+	before := time.Date(2025, 1, 15, 1, 0, 0, 0, types.SanFranciscoLocation())
+	after := time.Date(2025, 1, 15, 3, 0, 0, 0, types.SanFranciscoLocation())
+
+	tests := []struct {
+		name   string
+		now    time.Time
+		counts map[string]int64
+		want   []string
+	}{
+		{"before due, yesterday missing", before, nil, []string{"producthunt 2025-01-14: missing"}},
+		{"before due, yesterday partial", before, map[string]int64{"2025-01-14": 3}, nil},
+		{"after due, yesterday short", after, map[string]int64{"2025-01-14": 3, "2025-01-15": 1},
+			[]string{"producthunt 2025-01-14: short (3 of 10)"}},
+		{"after due, today missing", after, map[string]int64{"2025-01-14": 10},
+			[]string{"producthunt 2025-01-15: missing"}},
+		{"after due, both stale", after, nil,
+			[]string{"producthunt 2025-01-14: missing", "producthunt 2025-01-15: missing"}},
+		{"after due, fresh", after, map[string]int64{"2025-01-14": 10, "2025-01-15": 1}, nil},
+		{"now in another timezone", after.UTC(), map[string]int64{"2025-01-14": 10, "2025-01-15": 4}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, p := range evaluate(e, tt.counts, tt.now) {
+				got = append(got, p.String())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectationsFromEnv(t *testing.T) {
+	t.Setenv("FRESHNESS_PLATFORMS", "producthunt")
+	t.Setenv("PH_TOP_N", "")
+	t.Setenv("FRESHNESS_DUE", "3h")
+
+	t.Setenv("FRESHNESS_DUE_PRODUCTHUNT", "")
+	expectations, err := ExpectationsFromEnv()
+	if err != nil {
+		t.Fatalf("ExpectationsFromEnv: %v", err)
+	}
+	if len(expectations) != 1 || expectations[0].Due != 3*time.Hour {
+		t.Errorf("expectations = %+v, want producthunt due after the global 3h", expectations)
+	}
+
+	t.Setenv("FRESHNESS_DUE_PRODUCTHUNT", "90m")
+	expectations, err = ExpectationsFromEnv()
+	if err != nil {
+		t.Fatalf("ExpectationsFromEnv: %v", err)
+	}
+	if len(expectations) != 1 || expectations[0].Due != 90*time.Minute {
+		t.Errorf("expectations = %+v, want producthunt due after its own 90m", expectations)
+	}
+
+	t.Setenv("FRESHNESS_DUE_PRODUCTHUNT", "25h")
+	if _, err := ExpectationsFromEnv(); err == nil {
+		t.Error("ExpectationsFromEnv with a due time of 25h succeeded, want an error")
+	}
+}
@@ -0,0 +1,186 @@
+package freshness
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DefaultInterval is how often the monitor checks the stored rankings.
+const DefaultInterval = 10 * time.Minute
+
+// alertLockKey is the Postgres advisory lock of the monitor that sends the alerts.
+const alertLockKey = "huntline:freshness-alerts"
+
+// Monitor checks the stored rankings periodically, alerts the sinks when the set of problems
+// changes and keeps the latest problems for the stale data banner. Every web server replica
+// runs a monitor for its banner, but only the one holding the alert lock sends alerts.
+type Monitor struct {
+	db           *gorm.DB
+	expectations []Expectation
+	sinks        []Sink
+	interval     time.Duration
+
+	mu       sync.Mutex
+	problems []Problem
+	alerted  map[string]bool
+	// lockConn holds the alert lock once this monitor took it
+	lockConn *sql.Conn
+}
+
+// NewMonitor returns a monitor checking the expectations every interval.
+func NewMonitor(db *gorm.DB, expectations []Expectation, sinks []Sink, interval time.Duration) *Monitor {
+	return &Monitor{
+		db:           db,
+		expectations: expectations,
+		sinks:        sinks,
+		interval:     interval,
+		alerted:      make(map[string]bool),
+	}
+}
+
+// NewMonitorFromEnv returns a monitor configured by ExpectationsFromEnv, SinksFromEnv and
+// FRESHNESS_INTERVAL (default 10m).
+func NewMonitorFromEnv(db *gorm.DB) (*Monitor, error) {
+	expectations, err := ExpectationsFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	sinks, err := SinksFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	interval := DefaultInterval
+	if value := os.Getenv("FRESHNESS_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("invalid FRESHNESS_INTERVAL %q: expected a positive duration", value)
+		}
+	}
+	return NewMonitor(db, expectations, sinks, interval), nil
+}
+
+// Run checks immediately and then every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Problems returns the problems found by the latest check.
+func (m *Monitor) Problems() []Problem {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Problem(nil), m.problems...)
+}
+
+// check runs one check and alerts on new problems, or on recovery once every problem is gone.
+// A check that fails keeps the previous problems.
+func (m *Monitor) check(ctx context.Context) {
+	problems, err := Check(m.db.WithContext(ctx), m.expectations, time.Now())
+	if err != nil {
+		slog.Error("Error checking data freshness", "error", err)
+		return
+	}
+	m.update(ctx, problems)
+}
+
+// update keeps the problems of a check and sends the alert they call for. Problems only count as
+// alerted once the alert is sent, so an alert that fails is sent again on the next check.
+func (m *Monitor) update(ctx context.Context, problems []Problem) {
+	sort.Slice(problems, func(i, j int) bool { return problems[i].key() < problems[j].key() })
+
+	m.mu.Lock()
+	m.problems = problems
+	current := make(map[string]bool, len(problems))
+	isNew := false
+	for _, p := range problems {
+		current[p.key()] = true
+		if !m.alerted[p.key()] {
+			isNew = true
+		}
+	}
+	resolved := len(problems) == 0 && len(m.alerted) > 0
+	m.mu.Unlock()
+
+	if (isNew || resolved) && m.holdsAlertLock(ctx) {
+		alert := Alert{Problems: problems, At: time.Now()}
+		if !isNew {
+			alert = Alert{Resolved: true, At: time.Now()}
+		}
+		if err := m.alert(ctx, alert); err != nil {
+			return
+		}
+	}
+
+	m.mu.Lock()
+	m.alerted = current
+	m.mu.Unlock()
+}
+
+// holdsAlertLock reports whether this monitor sends the alerts, taking the alert lock on a
+// dedicated connection when it is free, e.g. after the replica holding it stopped. SQLite runs a
+// single web server, so its monitor always alerts.
+func (m *Monitor) holdsAlertLock(ctx context.Context) bool {
+	if m.db.Dialector.Name() != "postgres" {
+		return true
+	}
+	if m.lockConn != nil {
+		// A lost session lost the lock with it
+		if m.lockConn.PingContext(ctx) == nil {
+			return true
+		}
+		m.lockConn.Close()
+		m.lockConn = nil
+	}
+
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		slog.Error("Error taking the freshness alert lock", "error", err)
+		return false
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		slog.Error("Error taking the freshness alert lock", "error", err)
+		return false
+	}
+	var acquired bool
+	err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", alertLockKey).Scan(&acquired)
+	if err != nil || !acquired {
+		if err != nil {
+			slog.Error("Error taking the freshness alert lock", "error", err)
+		}
+		conn.Close()
+		return false
+	}
+	m.lockConn = conn
+	return true
+}
+
+// alert sends the alert to every sink. A failing sink does not stop the others; the error of the
+// last one that failed is returned.
+func (m *Monitor) alert(ctx context.Context, alert Alert) error {
+	var failed error
+	for _, sink := range m.sinks {
+		err := sink.Send(ctx, alert)
+		if err != nil {
+			slog.Error("Error sending freshness alert", "sink", fmt.Sprintf("%T", sink), "error", err)
+			failed = err
+		}
+	}
+	return failed
+}
@@ -0,0 +1,114 @@
+package freshness
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+)
+
+// recordingSink keeps the alerts it is sent, or fails while err is set.
+type recordingSink struct {
+	alerts []Alert
+	err    error
+}
+
+func (s *recordingSink) Send(ctx context.Context, alert Alert) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.alerts = append(s.alerts, alert)
+	return nil
+}
+
+func newTestMonitor(t *testing.T) (*Monitor, *recordingSink) {
+	t.Helper()
+	// Any database but Postgres alerts without taking the alert lock
+	db, err := gorm.Open(tests.DummyDialector{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink := &recordingSink{}
+	return NewMonitor(db, nil, []Sink{sink}, DefaultInterval), sink
+}
+
+var (
+	missingToday     = Problem{Platform: "producthunt", Date: "2025-01-15", Kind: Missing, Expected: 10}
+	shortYesterday   = Problem{Platform: "producthunt", Date: "2025-01-14", Kind: Short, Count: 3, Expected: 10}
+	shorterYesterday = Problem{Platform: "producthunt", Date: "2025-01-14", Kind: Short, Count: 5, Expected: 10}
+)
+
+func TestMonitorAlerts(t *testing.T) {
+	m, sink := newTestMonitor(t)
+	ctx := context.Background()
+
+	steps := []struct {
+		name     string
+		problems []Problem
+		// want is the alert the step sends: "stale", "resolved" or "" for none
+		want string
+	}{
+		{"fresh", nil, ""},
+		{"stale", []Problem{shortYesterday}, "stale"},
+		{"same problem", []Problem{shorterYesterday}, ""},
+		{"new problem", []Problem{shorterYesterday, missingToday}, "stale"},
+		{"one problem left", []Problem{missingToday}, ""},
+		{"recovered", nil, "resolved"},
+		{"still fresh", nil, ""},
+		{"stale again", []Problem{missingToday}, "stale"},
+	}
+	for _, step := range steps {
+		sent := len(sink.alerts)
+		m.update(ctx, step.problems)
+
+		got := ""
+		if len(sink.alerts) > sent+1 {
+			t.Fatalf("%s: sent %d alerts", step.name, len(sink.alerts)-sent)
+		}
+		if len(sink.alerts) == sent+1 {
+			got = "stale"
+			if sink.alerts[sent].Resolved {
+				got = "resolved"
+			}
+		}
+		if got != step.want {
+			t.Errorf("%s: sent %q, want %q", step.name, got, step.want)
+		}
+		if len(m.Problems()) != len(step.problems) {
+			t.Errorf("%s: Problems() = %v, want %v", step.name, m.Problems(), step.problems)
+		}
+	}
+}
+
+func TestMonitorRetriesFailedAlert(t *testing.T) {
+	m, sink := newTestMonitor(t)
+	ctx := context.Background()
+
+	sink.err = errors.New("webhook down")
+	m.update(ctx, []Problem{missingToday})
+	m.update(ctx, []Problem{missingToday})
+	if len(sink.alerts) != 0 {
+		t.Fatalf("failing sink recorded %d alerts", len(sink.alerts))
+	}
+
+	sink.err = nil
+	m.update(ctx, []Problem{missingToday})
+	if len(sink.alerts) != 1 || sink.alerts[0].Resolved {
+		t.Fatalf("alerts after the sink recovered = %+v, want the stale alert", sink.alerts)
+	}
+	m.update(ctx, []Problem{missingToday})
+	if len(sink.alerts) != 1 {
+		t.Errorf("stale alert sent %d times, want once", len(sink.alerts))
+	}
+
+	// A failed recovery alert is retried as well
+	sink.err = errors.New("webhook down")
+	m.update(ctx, nil)
+	sink.err = nil
+	m.update(ctx, nil)
+	if len(sink.alerts) != 2 || !sink.alerts[1].Resolved {
+		t.Errorf("alerts = %+v, want the retried recovery alert last", sink.alerts)
+	}
+}
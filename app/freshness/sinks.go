@@ -0,0 +1,154 @@
+package freshness
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/mail"
+)
+
+// Alert is sent when the stored rankings become stale or fresh again.
+type Alert struct {
+	// Resolved is set when every problem of the previous alert is gone
+	Resolved bool      `json:"resolved"`
+	Problems []Problem `json:"problems"`
+	At       time.Time `json:"at"`
+}
+
+// Summary returns a one-line description of the alert.
+func (a Alert) Summary() string {
+	if a.Resolved {
+		return "HuntLine data is fresh again"
+	}
+	return fmt.Sprintf("HuntLine data is stale: %d problem(s)", len(a.Problems))
+}
+
+// Text returns the summary followed by one line per problem.
+func (a Alert) Text() string {
+	lines := []string{a.Summary()}
+	for _, p := range a.Problems {
+		lines = append(lines, "- "+p.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Sink delivers freshness alerts.
+type Sink interface {
+	Send(ctx context.Context, alert Alert) error
+}
+
+// LogSink writes alerts to the default logger.
+type LogSink struct{}
+
+func (LogSink) Send(ctx context.Context, alert Alert) error {
+	if alert.Resolved {
+		slog.InfoContext(ctx, alert.Summary())
+		return nil
+	}
+	for _, p := range alert.Problems {
+		slog.ErrorContext(ctx, "Stale data", "platform", p.Platform, "date", p.Date, "kind", p.Kind,
+			"count", p.Count, "expected", p.Expected)
+	}
+	return nil
+}
+
+// WebhookSink posts alerts as JSON to a URL. The payload carries the alert fields and a
+// "text" summary, which Slack-compatible incoming webhooks display as the message.
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s WebhookSink) Send(ctx context.Context, alert Alert) error {
+	payload, err := json.Marshal(struct {
+		Alert
+		Text string `json:"text"`
+	}{alert, alert.Text()})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// EmailSink mails alerts to a list of recipients.
+type EmailSink struct {
+	Mail mail.Config
+	To   []string
+}
+
+func (s EmailSink) Send(ctx context.Context, alert Alert) error {
+	return s.Mail.Send(mail.Message{
+		To:      s.To,
+		Subject: alert.Summary(),
+		Text:    alert.Text(),
+	})
+}
+
+// SinksFromEnv builds the sinks named in FRESHNESS_ALERTS (comma separated: log, webhook, email;
+// default log). The webhook sink posts to FRESHNESS_WEBHOOK_URL, the email sink mails
+// FRESHNESS_EMAIL_TO (comma separated) through the SMTP server configured for mail.
+func SinksFromEnv() ([]Sink, error) {
+	names := "log"
+	if value, ok := os.LookupEnv("FRESHNESS_ALERTS"); ok {
+		names = value
+	}
+
+	var sinks []Sink
+	for _, name := range strings.Split(names, ",") {
+		switch name = strings.TrimSpace(name); name {
+		case "":
+		case "log":
+			sinks = append(sinks, LogSink{})
+		case "webhook":
+			url := os.Getenv("FRESHNESS_WEBHOOK_URL")
+			if url == "" {
+				return nil, fmt.Errorf("FRESHNESS_WEBHOOK_URL is required for the webhook alert sink")
+			}
+			sinks = append(sinks, WebhookSink{URL: url})
+		case "email":
+			cfg := mail.ConfigFromEnv()
+			if !cfg.Enabled() {
+				return nil, fmt.Errorf("SMTP_HOST and SMTP_FROM are required for the email alert sink")
+			}
+			var to []string
+			for _, addr := range strings.Split(os.Getenv("FRESHNESS_EMAIL_TO"), ",") {
+				if addr = strings.TrimSpace(addr); addr != "" {
+					to = append(to, addr)
+				}
+			}
+			if len(to) == 0 {
+				return nil, fmt.Errorf("FRESHNESS_EMAIL_TO is required for the email alert sink")
+			}
+			sinks = append(sinks, EmailSink{Mail: cfg, To: to})
+		default:
+			return nil, fmt.Errorf("unknown alert sink %q in FRESHNESS_ALERTS, expected log, webhook or email", name)
+		}
+	}
+	return sinks, nil
+}
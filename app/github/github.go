@@ -0,0 +1,193 @@
+// Package github reads the public facts of repositories, such as stars, license and last
+// commit, from the GitHub REST API.
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/targeturl"
+)
+
+// DefaultBaseURL is the root of the GitHub REST API.
+const DefaultBaseURL = "https://api.github.com"
+
+// ErrNotFound is returned for a repository that does not exist or is private.
+var ErrNotFound = errors.New("repository not found")
+
+// RateLimitError is returned when the API refuses requests until Reset.
+type RateLimitError struct {
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded until %s", e.Reset.Format(time.RFC3339))
+}
+
+// defaultClient gives up on a request after 15 seconds.
+var defaultClient = &http.Client{Timeout: 15 * time.Second}
+
+// Client calls the GitHub REST API.
+type Client struct {
+	// BaseURL is the root of the API (default DefaultBaseURL)
+	BaseURL string
+	// Token authenticates the requests, raising the rate limit from 60 to 5000 an hour
+	Token string
+	// HTTP sends the requests; nil uses a client with a 15 second timeout
+	HTTP *http.Client
+}
+
+// NewClientFromEnv creates a Client for GITHUB_API_URL (default DefaultBaseURL) authenticated
+// with GITHUB_TOKEN, if set.
+func NewClientFromEnv() Client {
+	return Client{BaseURL: os.Getenv("GITHUB_API_URL"), Token: os.Getenv("GITHUB_TOKEN")}
+}
+
+// Repo holds the facts of a repository.
+type Repo struct {
+	FullName string
+	Stars    int
+	Forks    int
+	// License is the SPDX identifier of the license, empty when GitHub does not recognize it
+	License  string
+	Language string
+	Archived bool
+	// LastCommitAt is the date of the last commit on the default branch
+	LastCommitAt time.Time
+}
+
+// ParseRepoURL returns the owner and name of the repository a github.com URL points into,
+// e.g. dariubs/huntline for https://github.com/dariubs/huntline/tree/main.
+func ParseRepoURL(raw string) (owner, name string, ok bool) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", false
+	}
+	if strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.") != "github.com" {
+		return "", "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+}
+
+// Repo fetches the facts of the repository owner/name.
+func (c Client) Repo(ctx context.Context, owner, name string) (Repo, error) {
+	var repo struct {
+		FullName        string    `json:"full_name"`
+		StargazersCount int       `json:"stargazers_count"`
+		ForksCount      int       `json:"forks_count"`
+		Language        string    `json:"language"`
+		Archived        bool      `json:"archived"`
+		DefaultBranch   string    `json:"default_branch"`
+		PushedAt        time.Time `json:"pushed_at"`
+		License         *struct {
+			SPDXID string `json:"spdx_id"`
+		} `json:"license"`
+	}
+	path := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(name)
+	err := c.get(ctx, path, &repo)
+	if err != nil {
+		return Repo{}, err
+	}
+
+	r := Repo{
+		FullName:     repo.FullName,
+		Stars:        repo.StargazersCount,
+		Forks:        repo.ForksCount,
+		Language:     repo.Language,
+		Archived:     repo.Archived,
+		LastCommitAt: repo.PushedAt,
+	}
+	// NOASSERTION is GitHub's SPDX identifier of a license it found but could not identify
+	if repo.License != nil && repo.License.SPDXID != "NOASSERTION" {
+		r.License = repo.License.SPDXID
+	}
+
+	// A push may be of another branch or a tag, the commit date is the one that matters. An
+	// empty repository has no commits, then the push date stays.
+	var commits []struct {
+		Commit struct {
+			Committer struct {
+				Date time.Time `json:"date"`
+			} `json:"committer"`
+		} `json:"commit"`
+	}
+	query := url.Values{"per_page": {"1"}}
+	if repo.DefaultBranch != "" {
+		query.Set("sha", repo.DefaultBranch)
+	}
+	err = c.get(ctx, "/repos/"+repo.FullName+"/commits?"+query.Encode(), &commits)
+	var rateLimit *RateLimitError
+	if errors.As(err, &rateLimit) {
+		return Repo{}, err
+	}
+	if err == nil && len(commits) > 0 {
+		r.LastCommitAt = commits[0].Commit.Committer.Date
+	}
+	return r, nil
+}
+
+// get sends a GET request for path and decodes the JSON response into v.
+func (c Client) get(ctx context.Context, path string, v any) error {
+	base := c.BaseURL
+	if base == "" {
+		base = DefaultBaseURL
+	}
+	client := c.HTTP
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(base, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", targeturl.UserAgent)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return json.NewDecoder(resp.Body).Decode(v)
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusTooManyRequests ||
+		resp.StatusCode == http.StatusForbidden && resp.Header.Get("X-RateLimit-Remaining") == "0":
+		reset := time.Now().Add(time.Hour)
+		seconds, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+		if err == nil {
+			reset = time.Unix(seconds, 0)
+		}
+		return &RateLimitError{Reset: reset}
+	default:
+		return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+}
+
+// StatusError is returned for an unexpected response status.
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected status " + e.Status
+}
@@ -0,0 +1,192 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// apiServer stands in for the GitHub API, serving each path with its handler.
+func apiServer(t *testing.T, routes map[string]http.HandlerFunc) Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := routes[r.URL.Path]
+		if !ok {
+			t.Errorf("unexpected request %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		route(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return Client{BaseURL: server.URL, Token: "secret", HTTP: server.Client()}
+}
+
+// reply answers with a status and a JSON body.
+func reply(status int, body string, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+const repoJSON = `{
+	"full_name": "acme/widget",
+	"stargazers_count": 1200,
+	"forks_count": 34,
+	"language": "Go",
+	"archived": false,
+	"default_branch": "main",
+	"pushed_at": "2025-01-20T10:00:00Z",
+	"license": {"spdx_id": "MIT"}
+}`
+
+func TestRepo(t *testing.T) {
+	client := apiServer(t, map[string]http.HandlerFunc{
+		"/repos/acme/widget": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+				t.Errorf("Authorization = %q", got)
+			}
+			reply(http.StatusOK, repoJSON)(w, r)
+		},
+		"/repos/acme/widget/commits": func(w http.ResponseWriter, r *http.Request) {
+			if got := r.URL.Query().Get("sha"); got != "main" {
+				t.Errorf("commits of branch %q, want the default branch", got)
+			}
+			reply(http.StatusOK, `[{"commit": {"committer": {"date": "2025-01-18T08:30:00Z"}}}]`)(w, r)
+		},
+	})
+
+	repo, err := client.Repo(context.Background(), "acme", "widget")
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	want := Repo{
+		FullName:     "acme/widget",
+		Stars:        1200,
+		Forks:        34,
+		License:      "MIT",
+		Language:     "Go",
+		LastCommitAt: time.Date(2025, 1, 18, 8, 30, 0, 0, time.UTC),
+	}
+	if repo != want {
+		t.Errorf("Repo = %+v, want %+v", repo, want)
+	}
+}
+
+func TestRepoUnrecognizedLicense(t *testing.T) {
+	client := apiServer(t, map[string]http.HandlerFunc{
+		"/repos/acme/widget":         reply(http.StatusOK, `{"full_name": "acme/widget", "license": {"spdx_id": "NOASSERTION"}}`),
+		"/repos/acme/widget/commits": reply(http.StatusOK, `[]`),
+	})
+
+	repo, err := client.Repo(context.Background(), "acme", "widget")
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	if repo.License != "" {
+		t.Errorf("License = %q, want none for NOASSERTION", repo.License)
+	}
+}
+
+func TestRepoEmpty(t *testing.T) {
+	// GitHub answers 409 for the commits of an empty repository
+	client := apiServer(t, map[string]http.HandlerFunc{
+		"/repos/acme/widget":         reply(http.StatusOK, repoJSON),
+		"/repos/acme/widget/commits": reply(http.StatusConflict, `{"message": "Git Repository is empty."}`),
+	})
+
+	repo, err := client.Repo(context.Background(), "acme", "widget")
+	if err != nil {
+		t.Fatalf("Repo: %v", err)
+	}
+	if want := time.Date(2025, 1, 20, 10, 0, 0, 0, time.UTC); !repo.LastCommitAt.Equal(want) {
+		t.Errorf("LastCommitAt = %v, want the push date %v", repo.LastCommitAt, want)
+	}
+}
+
+func TestRepoErrors(t *testing.T) {
+	reset := time.Date(2025, 1, 20, 11, 0, 0, 0, time.UTC)
+	resetHeader := "1737370800"
+
+	tests := []struct {
+		name  string
+		route http.HandlerFunc
+		check func(t *testing.T, err error)
+	}{
+		{
+			name:  "not found",
+			route: reply(http.StatusNotFound, `{"message": "Not Found"}`),
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("error = %v, want ErrNotFound", err)
+				}
+			},
+		},
+		{
+			name:  "403 rate limit",
+			route: reply(http.StatusForbidden, `{}`, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", resetHeader),
+			check: func(t *testing.T, err error) {
+				var rateLimit *RateLimitError
+				if !errors.As(err, &rateLimit) {
+					t.Fatalf("error = %v, want a RateLimitError", err)
+				}
+				if !rateLimit.Reset.Equal(reset) {
+					t.Errorf("Reset = %v, want %v", rateLimit.Reset, reset)
+				}
+			},
+		},
+		{
+			name:  "429",
+			route: reply(http.StatusTooManyRequests, `{}`),
+			check: func(t *testing.T, err error) {
+				var rateLimit *RateLimitError
+				if !errors.As(err, &rateLimit) {
+					t.Fatalf("error = %v, want a RateLimitError", err)
+				}
+				// Without a reset header the client waits an hour
+				if time.Until(rateLimit.Reset) < 59*time.Minute {
+					t.Errorf("Reset = %v, want about an hour from now", rateLimit.Reset)
+				}
+			},
+		},
+		{
+			name:  "403 forbidden",
+			route: reply(http.StatusForbidden, `{}`, "X-RateLimit-Remaining", "42"),
+			check: func(t *testing.T, err error) {
+				var status *StatusError
+				if !errors.As(err, &status) || status.StatusCode != http.StatusForbidden {
+					t.Errorf("error = %v, want a 403 StatusError", err)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := apiServer(t, map[string]http.HandlerFunc{"/repos/acme/widget": tt.route})
+			_, err := client.Repo(context.Background(), "acme", "widget")
+			tt.check(t, err)
+		})
+	}
+}
+
+func TestRepoRateLimitedCommits(t *testing.T) {
+	// A rate limit on the second request fails the repository rather than keeping the push date
+	client := apiServer(t, map[string]http.HandlerFunc{
+		"/repos/acme/widget":         reply(http.StatusOK, repoJSON),
+		"/repos/acme/widget/commits": reply(http.StatusTooManyRequests, `{}`),
+	})
+
+	_, err := client.Repo(context.Background(), "acme", "widget")
+	var rateLimit *RateLimitError
+	if !errors.As(err, &rateLimit) {
+		t.Errorf("error = %v, want a RateLimitError", err)
+	}
+}
@@ -1,6 +1,7 @@
 package huntline
 
 import (
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -12,10 +13,15 @@ import (
 
 func ArchiveHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		platform := c.Query("platform")
 		if platform == "" {
 			platform = "producthunt" // Default to producthunt
 		}
+		limit := limitParam(c, DefaultDayLimit)
+		// A language filter implies the open-source filter
+		language := c.Query("language")
+		openSource := c.Query("oss") == "1" || language != ""
 
 		// Get dates in San Francisco timezone (Pacific Time)
 		loc, err := time.LoadLocation("America/Los_Angeles")
@@ -44,42 +50,55 @@ func ArchiveHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		monthStart := time.Date(selectedMonth.Year(), selectedMonth.Month(), 1, 0, 0, 0, 0, loc)
 		monthEnd := monthStart.AddDate(0, 1, 0).AddDate(0, 0, -1)
 
-		// Get all distinct dates for the platform in the selected month, ordered by date descending
-		var dates []struct {
-			Date time.Time
-		}
-
-		query := db.Model(&model.Product{}).Select("DISTINCT date").
+		// Get the products of every date of the month in one query, newest date first
+		var products []model.Product
+		productQuery := db.Scopes(model.Ranked).
 			Where("date >= ? AND date <= ?", monthStart.Format("2006-01-02"), monthEnd.Format("2006-01-02")).
-			Order("date DESC")
+			Where("rank <= ?", limit).Order("date DESC, rank ASC")
 		if platform != "all" {
-			query = query.Where("platform = ?", platform)
+			productQuery = productQuery.Where("platform = ?", platform)
 		}
-		query.Scan(&dates)
+		if openSource {
+			productQuery = productQuery.Scopes(model.OpenSource(language))
+		}
+		productQuery.Find(&products)
+		attachDecorations(db, products)
 
-		// For each date, get products
+		// The products of a date
 		type DateGroup struct {
 			Date     time.Time
 			DateStr  string
 			Products []model.Product
+			Final    bool
+		}
+
+		// Days whose rankings have settled; the rest are shown as provisional
+		finalDays, err := model.FinalDays(db, monthStart.Format("2006-01-02"), monthEnd.Format("2006-01-02"))
+		if err != nil {
+			c.String(500, "Failed to load rankings status")
+			return
 		}
 
+		// Group the products by date, in the order they were loaded
 		var dateGroups []DateGroup
-		for _, d := range dates {
-			var products []model.Product
-			productQuery := db.Where("date = ?", d.Date.Format("2006-01-02")).Order("rank ASC")
-			if platform != "all" {
-				productQuery = productQuery.Where("platform = ?", platform)
-			}
-			productQuery.Find(&products)
-
-			if len(products) > 0 {
-				dateGroups = append(dateGroups, DateGroup{
-					Date:     d.Date,
-					DateStr:  d.Date.Format("2006-01-02"),
-					Products: products,
-				})
+		for start := 0; start < len(products); {
+			date := products[start].Date
+			end := start
+			// A date is final once every platform shown for it has settled
+			final := true
+			for ; end < len(products) && products[end].Date.Equal(date); end++ {
+				if !finalDays[model.DayKey(products[end].Platform, date)] {
+					final = false
+				}
 			}
+
+			dateGroups = append(dateGroups, DateGroup{
+				Date:     date,
+				DateStr:  date.Format("2006-01-02"),
+				Products: products[start:end],
+				Final:    final,
+			})
+			start = end
 		}
 
 		// Calculate previous and next month for navigation
@@ -89,6 +108,21 @@ func ArchiveHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		// Check if next month is in the future
 		nextMonthInFuture := nextMonth.After(now)
 
+		// Share of the websites launched over a year ago that are still up
+		alive, checked, err := aliveAfterYear(db, now)
+		if err != nil {
+			slog.Warn("Error counting live websites", "error", err)
+		}
+		var alivePercent int64
+		if checked > 0 {
+			alivePercent = alive * 100 / checked
+		}
+
+		languages, err := model.RepositoryLanguages(db, 8)
+		if err != nil {
+			slog.Warn("Error loading repository languages", "error", err)
+		}
+
 		c.HTML(http.StatusOK, "archive.html", gin.H{
 			"gd":                gd,
 			"dateGroups":        dateGroups,
@@ -99,6 +133,14 @@ func ArchiveHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			"nextMonth":         nextMonth.Format("2006-01"),
 			"nextMonthInFuture": nextMonthInFuture,
 			"currentPage":       "archive",
+			"limit":             limit,
+			"customLimit":       limit != DefaultDayLimit,
+			"moreLimit":         moreLimit(limit),
+			"aliveChecked":      checked,
+			"alivePercent":      alivePercent,
+			"openSource":        openSource,
+			"language":          language,
+			"languages":         languages,
 		})
 	}
 }
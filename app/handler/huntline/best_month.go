@@ -12,8 +12,10 @@ import (
 
 func BestMonthHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		// Get month/year from query params or use current month
 		monthStr := c.DefaultQuery("month", "")
+		limit := limitParam(c, DefaultBestLimit)
 		// Get dates in San Francisco timezone (Pacific Time)
 		loc, err := time.LoadLocation("America/Los_Angeles")
 		if err != nil {
@@ -39,10 +41,12 @@ func BestMonthHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 
 		// Get all products for the month, grouped by platform
 		var allProducts []model.Product
-		db.Where("date >= ? AND date <= ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		db.Scopes(model.Ranked).Where("date >= ? AND date <= ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
 			Order("platform ASC, rank ASC").
 			Find(&allProducts)
 
+		attachDecorations(db, allProducts)
+
 		// Group by platform and get top products per platform
 		type PlatformBest struct {
 			Platform string
@@ -70,7 +74,7 @@ func BestMonthHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 				uniqueProducts = append(uniqueProducts, p)
 			}
 
-			// Sort by rank and take top 20
+			// Sort by rank and take top limit
 			for i := 0; i < len(uniqueProducts)-1; i++ {
 				for j := i + 1; j < len(uniqueProducts); j++ {
 					if uniqueProducts[i].Rank > uniqueProducts[j].Rank {
@@ -79,8 +83,8 @@ func BestMonthHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 				}
 			}
 
-			if len(uniqueProducts) > 20 {
-				uniqueProducts = uniqueProducts[:20]
+			if len(uniqueProducts) > limit {
+				uniqueProducts = uniqueProducts[:limit]
 			}
 
 			platformBests = append(platformBests, PlatformBest{
@@ -94,6 +98,7 @@ func BestMonthHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			"platforms": platformBests,
 			"month":     startDate.Format("January 2006"),
 			"monthStr":  startDate.Format("2006-01"),
+			"limit":     limit,
 		})
 	}
 }
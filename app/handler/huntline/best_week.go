@@ -12,6 +12,7 @@ import (
 
 func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		// Get dates in San Francisco timezone (Pacific Time)
 		loc, err := time.LoadLocation("America/Los_Angeles")
 		if err != nil {
@@ -19,6 +20,7 @@ func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			return
 		}
 		now := time.Now().In(loc)
+		limit := limitParam(c, DefaultBestLimit)
 
 		// Get start of current week (Monday)
 		weekday := int(now.Weekday())
@@ -32,10 +34,12 @@ func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 
 		// Get all products for the week, grouped by platform
 		var allProducts []model.Product
-		db.Where("date >= ? AND date <= ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
+		db.Scopes(model.Ranked).Where("date >= ? AND date <= ?", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")).
 			Order("platform ASC, rank ASC").
 			Find(&allProducts)
 
+		attachDecorations(db, allProducts)
+
 		// Group by platform and get top products per platform
 		type PlatformBest struct {
 			Platform string
@@ -63,7 +67,7 @@ func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 				uniqueProducts = append(uniqueProducts, p)
 			}
 
-			// Sort by rank and take top 20
+			// Sort by rank and take top limit
 			for i := 0; i < len(uniqueProducts)-1; i++ {
 				for j := i + 1; j < len(uniqueProducts); j++ {
 					if uniqueProducts[i].Rank > uniqueProducts[j].Rank {
@@ -72,8 +76,8 @@ func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 				}
 			}
 
-			if len(uniqueProducts) > 20 {
-				uniqueProducts = uniqueProducts[:20]
+			if len(uniqueProducts) > limit {
+				uniqueProducts = uniqueProducts[:limit]
 			}
 
 			platformBests = append(platformBests, PlatformBest{
@@ -87,6 +91,7 @@ func BestWeekHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			"platforms": platformBests,
 			"weekStart": startDate.Format("January 2"),
 			"weekEnd":   endDate.Format("January 2, 2006"),
+			"limit":     limit,
 		})
 	}
 }
@@ -0,0 +1,197 @@
+package huntline
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dariubs/huntline/app/metrics"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// clickWindow is how long repeated clicks of a visitor on the same link count once.
+const clickWindow = 30 * time.Minute
+
+// botAgents are parts of the user agents of crawlers and link previewers, whose visits are not clicks.
+var botAgents = []string{"bot", "crawl", "spider", "slurp", "preview", "facebookexternalhit", "curl", "wget", "python-requests", "go-http-client"}
+
+// clickFilter drops repeated clicks without keeping anything that identifies visitors: it keeps
+// salted hashes of the visitor and link for clickWindow, in memory only, and replaces the salt
+// every day so the hashes can not be linked across days.
+type clickFilter struct {
+	mu      sync.Mutex
+	salt    []byte
+	saltDay string
+	seen    map[[sha256.Size]byte]time.Time
+	// order holds the keys of seen oldest first, so expired keys are dropped from its front
+	order []clickKey
+}
+
+// clickKey is a key of clickFilter.seen and when it was seen.
+type clickKey struct {
+	key [sha256.Size]byte
+	at  time.Time
+}
+
+// first reports whether this is the first click of the visitor on the link within clickWindow.
+func (f *clickFilter) first(visitor, userAgent string, productID uint, now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	day := now.UTC().Format("2006-01-02")
+	if f.saltDay != day {
+		f.salt = make([]byte, 32)
+		rand.Read(f.salt)
+		f.saltDay = day
+		f.seen = make(map[[sha256.Size]byte]time.Time)
+		f.order = nil
+	}
+	for len(f.order) > 0 && now.Sub(f.order[0].at) > clickWindow {
+		delete(f.seen, f.order[0].key)
+		f.order = f.order[1:]
+	}
+
+	h := sha256.New()
+	h.Write(f.salt)
+	h.Write([]byte(visitor + "\x00" + userAgent + "\x00" + strconv.FormatUint(uint64(productID), 10)))
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	if _, ok := f.seen[key]; ok {
+		return false
+	}
+	f.seen[key] = now
+	f.order = append(f.order, clickKey{key: key, at: now})
+	return true
+}
+
+// isBot reports whether a user agent belongs to a crawler or link previewer.
+func isBot(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	if userAgent == "" {
+		return true
+	}
+	for _, bot := range botAgents {
+		if strings.Contains(userAgent, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// utmFromEnv returns the UTM parameters added to outbound links from UTM_SOURCE, UTM_MEDIUM and
+// UTM_CAMPAIGN; none are added when they are unset.
+func utmFromEnv() url.Values {
+	utm := url.Values{}
+	for param, env := range map[string]string{"utm_source": "UTM_SOURCE", "utm_medium": "UTM_MEDIUM", "utm_campaign": "UTM_CAMPAIGN"} {
+		if value := os.Getenv(env); value != "" {
+			utm.Set(param, value)
+		}
+	}
+	return utm
+}
+
+// withUTM adds the UTM parameters to a link, keeping those it already has.
+func withUTM(link string, utm url.Values) string {
+	if len(utm) == 0 {
+		return link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	query := u.Query()
+	for param, values := range utm {
+		if !query.Has(param) {
+			query[param] = values
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// GoHandler counts a click on a product's link and redirects to the product's website.
+func GoHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	utm := utmFromEnv()
+	filter := &clickFilter{}
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		var product model.Product
+		err = db.Select("id", "url").Limit(1).Find(&product, id).Error
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load product")
+			return
+		}
+		// Only redirect to web links, never to whatever scheme a platform returned
+		target, err := url.Parse(product.URL)
+		if product.ID == 0 || err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		now := time.Now()
+		if c.Request.Method == http.MethodGet && !isBot(c.Request.UserAgent()) &&
+			filter.first(c.ClientIP(), c.Request.UserAgent(), product.ID, now) {
+			// A lost click is not worth failing the redirect for
+			err = model.RecordClick(db, product.ID, now.In(types.SanFranciscoLocation()).Format("2006-01-02"))
+			if err != nil {
+				slog.Warn("Error recording click", "product", product.ID, "error", err)
+			} else {
+				metrics.OutboundClicks.Inc()
+			}
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, withUTM(product.URL, utm))
+	}
+}
+
+// MostClickedHandler shows the products visitors clicked most over the last 7 or 30 days.
+func MostClickedHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		days := 30
+		if c.Query("days") == "7" {
+			days = 7
+		}
+		limit := limitParam(c, DefaultBestLimit)
+		since := time.Now().In(types.SanFranciscoLocation()).AddDate(0, 0, -days+1).Format("2006-01-02")
+
+		ranking, err := model.MostClicked(db, since, limit)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load clicks")
+			return
+		}
+
+		products := make([]model.Product, len(ranking))
+		for i := range ranking {
+			products[i] = ranking[i].Product
+		}
+		attachDecorations(db, products)
+		for i := range ranking {
+			ranking[i].Product = products[i]
+		}
+
+		c.HTML(http.StatusOK, "clicks.html", gin.H{
+			"gd":      gd,
+			"ranking": ranking,
+			"days":    days,
+			"limit":   limit,
+		})
+	}
+}
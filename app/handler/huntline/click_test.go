@@ -0,0 +1,33 @@
+package huntline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClickFilter(t *testing.T) {
+	f := &clickFilter{}
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	if !f.first("203.0.113.1", "Firefox", 1, now) {
+		t.Fatal("first click was dropped")
+	}
+	if f.first("203.0.113.1", "Firefox", 1, now.Add(time.Minute)) {
+		t.Error("repeated click was counted")
+	}
+	if !f.first("203.0.113.1", "Firefox", 2, now.Add(time.Minute)) {
+		t.Error("click on another link was dropped")
+	}
+	if !f.first("203.0.113.2", "Firefox", 1, now.Add(time.Minute)) {
+		t.Error("click of another visitor was dropped")
+	}
+
+	// Clicks are forgotten after clickWindow
+	later := now.Add(clickWindow + 2*time.Minute)
+	if !f.first("203.0.113.1", "Firefox", 1, later) {
+		t.Error("click after the window was dropped")
+	}
+	if len(f.seen) != 1 || len(f.order) != 1 {
+		t.Errorf("filter keeps %d keys in %d entries, want only the last click", len(f.seen), len(f.order))
+	}
+}
@@ -0,0 +1,60 @@
+package huntline
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"gorm.io/gorm"
+)
+
+// attachDecorations loads what pages show next to a product: its website status, launches on other
+// platforms, GitHub repository and website metadata. All of them are optional, so an error is
+// logged and the page is shown without them.
+func attachDecorations(db *gorm.DB, products []model.Product) {
+	err := model.AttachLinks(db, products)
+	if err != nil {
+		slog.Warn("Error loading website status", "error", err)
+	}
+	err = model.AttachAlsoLaunched(db, products)
+	if err != nil {
+		slog.Warn("Error loading launches on other platforms", "error", err)
+	}
+	err = model.AttachRepositories(db, products)
+	if err != nil {
+		slog.Warn("Error loading GitHub repositories", "error", err)
+	}
+	err = model.AttachMetadata(db, products)
+	if err != nil {
+		slog.Warn("Error loading website metadata", "error", err)
+	}
+}
+
+// aliveCacheTTL is how long the share of live websites is reused. Websites are checked weekly, so
+// it changes slowly.
+const aliveCacheTTL = time.Hour
+
+// aliveCache keeps the result of model.AliveAfterYear, which scans every product, between requests.
+var aliveCache struct {
+	mu      sync.Mutex
+	alive   int64
+	checked int64
+	expires time.Time
+}
+
+// aliveAfterYear returns model.AliveAfterYear, computed at most once per aliveCacheTTL.
+func aliveAfterYear(db *gorm.DB, now time.Time) (alive, checked int64, err error) {
+	aliveCache.mu.Lock()
+	defer aliveCache.mu.Unlock()
+	if now.Before(aliveCache.expires) {
+		return aliveCache.alive, aliveCache.checked, nil
+	}
+	alive, checked, err = model.AliveAfterYear(db, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	aliveCache.alive, aliveCache.checked = alive, checked
+	aliveCache.expires = now.Add(aliveCacheTTL)
+	return alive, checked, nil
+}
@@ -17,6 +17,7 @@ type PlatformProducts struct {
 
 func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		// Get dates in San Francisco timezone (Pacific Time)
 		loc, err := time.LoadLocation("America/Los_Angeles")
 		if err != nil {
@@ -29,6 +30,7 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		
 		// Get date parameter (format: YYYY-MM-DD) or use today as default
 		dateParam := c.DefaultQuery("date", "")
+		limit := limitParam(c, DefaultDayLimit)
 		var startDate, endDate time.Time
 		
 		if dateParam != "" {
@@ -45,7 +47,7 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			// Default: show today's products
 			// Redirect to include date parameter in URL for clarity
 			todayStr := today.Format("2006-01-02")
-			c.Redirect(302, "/?date="+todayStr)
+			c.Redirect(302, "/?date="+todayStr+limitQuery(limit, DefaultDayLimit))
 			return
 		}
 		
@@ -55,10 +57,20 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		endDateStr := endDate.Format("2006-01-02")
 		
 		var allProducts []model.Product
-		db.Where("date >= ? AND date <= ?", startDateStr, endDateStr).
+		db.Scopes(model.Ranked).Where("date >= ? AND date <= ?", startDateStr, endDateStr).
+			Where("rank <= ?", limit).
 			Order("platform ASC, date DESC, rank ASC").
 			Find(&allProducts)
+
+		attachDecorations(db, allProducts)
 		
+		// Days whose rankings have settled; the rest are shown as provisional
+		finalDays, err := model.FinalDays(db, startDateStr, endDateStr)
+		if err != nil {
+			c.String(500, "Failed to load rankings status")
+			return
+		}
+
 		// Calculate navigation dates
 		prevDay := startDate.AddDate(0, 0, -1)
 		nextDay := endDate.AddDate(0, 0, 1)
@@ -84,6 +96,7 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			Date     time.Time
 			DateStr  string
 			Products []model.Product
+			Final    bool
 		}
 		
 		type PlatformData struct {
@@ -111,6 +124,7 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 					Date:     parsedDate,
 					DateStr:  dateStr,
 					Products: products,
+					Final:    finalDays[model.DayKey(pp.Platform, parsedDate)],
 				})
 			}
 			
@@ -139,6 +153,8 @@ func IndexHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			"nextDayInFuture":  nextDayInFuture,
 			"startDate":        startDate.Format("2006-01-02"),
 			"endDate":          endDate.Format("2006-01-02"),
+			"limit":            limit,
+			"moreLimit":        moreLimit(limit),
 		})
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		// Get dates in San Francisco timezone (Pacific Time)
 		loc, err := time.LoadLocation("America/Los_Angeles")
 		if err != nil {
@@ -23,6 +24,7 @@ func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		
 		// Get date parameter (format: YYYY-MM-DD) or use today
 		dateParam := c.DefaultQuery("date", "")
+		limit := limitParam(c, DefaultDayLimit)
 		var startDate, endDate time.Time
 		
 		if dateParam != "" {
@@ -45,10 +47,20 @@ func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		endDateStr := endDate.Format("2006-01-02")
 		
 		var allProducts []model.Product
-		db.Where("date >= ? AND date <= ?", startDateStr, endDateStr).
+		db.Scopes(model.Ranked).Where("date >= ? AND date <= ?", startDateStr, endDateStr).
+			Where("rank <= ?", limit).
 			Order("platform ASC, date DESC, rank ASC").
 			Find(&allProducts)
+
+		attachDecorations(db, allProducts)
 		
+		// Days whose rankings have settled; the rest are shown as provisional
+		finalDays, err := model.FinalDays(db, startDateStr, endDateStr)
+		if err != nil {
+			c.JSON(500, gin.H{"error": "Failed to load rankings status"})
+			return
+		}
+
 		// Calculate navigation dates
 		prevDay := startDate.AddDate(0, 0, -1)
 		nextDay := endDate.AddDate(0, 0, 1)
@@ -68,6 +80,7 @@ func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			Date     time.Time
 			DateStr  string
 			Products []model.Product
+			Final    bool
 		}
 		
 		type PlatformData struct {
@@ -91,6 +104,7 @@ func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 					Date:     parsedDate,
 					DateStr:  dateStr,
 					Products: products,
+					Final:    finalDays[model.DayKey(platform, parsedDate)],
 				})
 			}
 			
@@ -113,6 +127,8 @@ func IndexAPIHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			"nextDayInFuture":  nextDayInFuture,
 			"startDate":        startDate.Format("2006-01-02"),
 			"endDate":          endDate.Format("2006-01-02"),
+			"limit":            limit,
+			"moreLimit":        moreLimit(limit),
 		})
 	}
 }
@@ -0,0 +1,46 @@
+package huntline
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// DefaultDayLimit is the number of products per day shown on the index and archive pages
+	DefaultDayLimit = 10
+	// DefaultBestLimit is the number of products per platform shown on the best-of pages
+	DefaultBestLimit = 20
+	// MaxLimit caps the optional limit query parameter
+	MaxLimit = 100
+)
+
+// limitParam reads the optional "limit" query parameter, falling back to def
+// when it is missing or invalid and capping it at MaxLimit.
+func limitParam(c *gin.Context, def int) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		return def
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// limitQuery returns the query string fragment that keeps a non-default limit in navigation links.
+func limitQuery(limit, def int) string {
+	if limit == def {
+		return ""
+	}
+	return fmt.Sprintf("&limit=%d", limit)
+}
+
+// moreLimit returns the limit to use for a "show more" link.
+func moreLimit(limit int) int {
+	if limit+DefaultDayLimit > MaxLimit {
+		return MaxLimit
+	}
+	return limit + DefaultDayLimit
+}
@@ -0,0 +1,89 @@
+package huntline
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/dariubs/huntline/app/logo"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/targeturl"
+	"github.com/dariubs/huntline/app/types"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// legacyLogoPrefix starts the logos stored before the proxy, which hot-linked Google's favicon service.
+const legacyLogoPrefix = "https://www.google.com/s2/favicons"
+
+// LogoHandler serves the logo of a product from the logo proxy, or a monogram when the product's
+// website has no usable logo.
+func LogoHandler(db *gorm.DB, gd types.General, logos *logo.Proxy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		var product model.Product
+		err = db.Select("id", "name", "url", "logo", "domain").Limit(1).Find(&product, id).Error
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load product")
+			return
+		}
+		if product.ID == 0 {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		// Products sharing a website share its logo
+		key := product.Domain
+		if key == "" {
+			key = targeturl.DomainKey(product.URL)
+		}
+		if key == "" {
+			key = fmt.Sprintf("product-%d", product.ID)
+		}
+
+		products := []model.Product{product}
+		err = model.AttachMetadata(db, products)
+		if err != nil {
+			slog.Warn("Error loading website metadata", "error", err)
+		}
+
+		data, err := logos.Logo(c.Request.Context(), key, logoSources(products[0]))
+		if err != nil {
+			if !errors.Is(err, logo.ErrNoLogo) {
+				slog.Warn("Error loading logo", "product", product.ID, "error", err)
+			}
+			c.Header("Cache-Control", "public, max-age=86400")
+			c.Data(http.StatusOK, "image/svg+xml", logo.Monogram(product.Name))
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=604800")
+		c.Data(http.StatusOK, "image/png", data)
+	}
+}
+
+// logoSources lists where the logo of a product may be found, best first: the icon its homepage
+// declares, the conventional /favicon.ico and the logo of the platform it launched on.
+func logoSources(product model.Product) []string {
+	var sources []string
+	if product.Metadata != nil && product.Metadata.Favicon != "" {
+		sources = append(sources, product.Metadata.Favicon)
+	}
+	u, err := url.Parse(product.URL)
+	if err == nil && u.Host != "" {
+		sources = append(sources, (&url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/favicon.ico"}).String())
+	}
+	if product.Logo != "" && !strings.HasPrefix(product.Logo, legacyLogoPrefix) {
+		sources = append(sources, product.Logo)
+	}
+	return sources
+}
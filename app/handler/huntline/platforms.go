@@ -2,6 +2,7 @@ package huntline
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/dariubs/huntline/app/model"
 	"github.com/dariubs/huntline/app/types"
@@ -11,6 +12,7 @@ import (
 
 func PlatformsHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
 		// Get all distinct platforms with statistics
 		type PlatformStats struct {
 			Platform     string
@@ -21,7 +23,7 @@ func PlatformsHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 		}
 
 		var platforms []string
-		db.Model(&model.Product{}).Distinct("platform").Pluck("platform", &platforms)
+		db.Model(&model.Product{}).Scopes(model.Ranked).Distinct("platform").Pluck("platform", &platforms)
 
 		var platformStatsList []PlatformStats
 		for _, platform := range platforms {
@@ -29,24 +31,24 @@ func PlatformsHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
 			var earliestDate, latestDate string
 			var dateCount int64
 
-			db.Model(&model.Product{}).Where("platform = ?", platform).Count(&productCount)
+			db.Model(&model.Product{}).Scopes(model.Ranked).Where("platform = ?", platform).Count(&productCount)
 
 			var dates []struct {
-				Date string
+				Date time.Time `gorm:"serializer:date"`
 			}
-			db.Model(&model.Product{}).Select("DISTINCT date").Where("platform = ?", platform).
+			db.Model(&model.Product{}).Scopes(model.Ranked).Select("DISTINCT date").Where("platform = ?", platform).
 				Order("date ASC").Limit(1).Scan(&dates)
 			if len(dates) > 0 {
-				earliestDate = dates[0].Date
+				earliestDate = dates[0].Date.Format("2006-01-02")
 			}
 
-			db.Model(&model.Product{}).Select("DISTINCT date").Where("platform = ?", platform).
+			db.Model(&model.Product{}).Scopes(model.Ranked).Select("DISTINCT date").Where("platform = ?", platform).
 				Order("date DESC").Limit(1).Scan(&dates)
 			if len(dates) > 0 {
-				latestDate = dates[0].Date
+				latestDate = dates[0].Date.Format("2006-01-02")
 			}
 
-			db.Model(&model.Product{}).Select("COUNT(DISTINCT date)").Where("platform = ?", platform).
+			db.Model(&model.Product{}).Scopes(model.Ranked).Select("COUNT(DISTINCT date)").Where("platform = ?", platform).
 				Scan(&dateCount)
 
 			platformStatsList = append(platformStatsList, PlatformStats{
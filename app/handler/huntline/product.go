@@ -0,0 +1,53 @@
+package huntline
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ProductHandler shows a product with its launches on every platform.
+func ProductHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		var product model.Product
+		err = db.Limit(1).Find(&product, id).Error
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to load product")
+			return
+		}
+		if product.ID == 0 {
+			c.String(http.StatusNotFound, "Product not found")
+			return
+		}
+
+		// Without an identity the product is known from this launch only
+		launches := []model.Product{product}
+		if product.IdentityID != nil {
+			launches, err = model.Launches(db, *product.IdentityID)
+			if err != nil {
+				c.String(http.StatusInternalServerError, "Failed to load launches")
+				return
+			}
+		}
+
+		products := []model.Product{product}
+		attachDecorations(db, products)
+
+		c.HTML(http.StatusOK, "product.html", gin.H{
+			"gd":       gd,
+			"product":  products[0],
+			"launches": launches,
+		})
+	}
+}
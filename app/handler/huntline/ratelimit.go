@@ -0,0 +1,55 @@
+package huntline
+
+import (
+	"sync"
+	"time"
+)
+
+// rateLimiter allows each key, such as a client address, limit requests per window. Counts are
+// kept in memory only, per web server replica.
+type rateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu      sync.Mutex
+	windows map[string]rateWindow
+	swept   time.Time
+}
+
+// rateWindow is the count of a key since start.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// newRateLimiter returns a limiter of limit requests per window and key.
+func newRateLimiter(limit int, window time.Duration) *rateLimiter {
+	return &rateLimiter{limit: limit, window: window, windows: make(map[string]rateWindow)}
+}
+
+// allow counts a request of key and reports whether it is within the limit.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Drop the finished windows once per window, not on every request
+	if now.Sub(l.swept) >= l.window {
+		for k, w := range l.windows {
+			if now.Sub(w.start) >= l.window {
+				delete(l.windows, k)
+			}
+		}
+		l.swept = now
+	}
+
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = rateWindow{start: now}
+	}
+	if w.count >= l.limit {
+		return false
+	}
+	w.count++
+	l.windows[key] = w
+	return true
+}
@@ -0,0 +1,30 @@
+package huntline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(2, time.Hour)
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	if !l.allow("a", now) || !l.allow("a", now.Add(time.Minute)) {
+		t.Fatal("requests within the limit were refused")
+	}
+	if l.allow("a", now.Add(2*time.Minute)) {
+		t.Error("third request in the window was allowed")
+	}
+	if !l.allow("b", now.Add(2*time.Minute)) {
+		t.Error("another key shares the limit")
+	}
+	if !l.allow("a", now.Add(time.Hour)) {
+		t.Error("request after the window was refused")
+	}
+
+	// Finished windows are dropped by the next sweep
+	l.allow("c", now.Add(3*time.Hour))
+	if _, ok := l.windows["b"]; ok {
+		t.Error("finished window was kept")
+	}
+}
@@ -0,0 +1,219 @@
+package huntline
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/digest"
+	"github.com/dariubs/huntline/app/mail"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// emailTemplates is the directory of the digest and confirmation email templates.
+const emailTemplates = "view/email"
+
+// Limits of subscription requests, so the form can not be used to mail strangers repeatedly.
+const (
+	subscribeLimitPerIP      = 10
+	subscribeLimitPerAddress = 3
+	subscribeLimitWindow     = time.Hour
+)
+
+// subscribePage is the content of the subscription page.
+type subscribePage struct {
+	Title    string
+	Message  string
+	ShowForm bool
+	// UnsubscribeToken shows the unsubscribe button of the subscription with this token
+	UnsubscribeToken string
+}
+
+// renderSubscribe renders the subscription page.
+func renderSubscribe(c *gin.Context, code int, gd types.General, page subscribePage) {
+	c.HTML(code, "subscribe.html", gin.H{
+		"gd":               gd,
+		"title":            page.Title,
+		"message":          page.Message,
+		"showForm":         page.ShowForm,
+		"unsubscribeToken": page.UnsubscribeToken,
+		"email":            c.PostForm("email"),
+		"frequency":        c.DefaultPostForm("frequency", model.FrequencyDaily),
+	})
+}
+
+// SubscribePageHandler shows the subscription form.
+func SubscribePageHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderSubscribe(c, http.StatusOK, gd, subscribePage{
+			Title:    "Get the top products by email",
+			Message:  "Pick a daily or weekly digest of the top launches. We'll send a link to confirm your address first.",
+			ShowForm: true,
+		})
+	}
+}
+
+// SubscribeHandler records a subscription and mails the confirmation link (double opt-in).
+// The response is the same whether or not the address was already subscribed, or its
+// confirmation was already sent.
+func SubscribeHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	mailConfig := mail.ConfigFromEnv()
+	templates, templatesErr := digest.LoadTemplates(emailTemplates)
+	if mailConfig.Enabled() && templatesErr != nil {
+		slog.Error("Error loading email templates, subscriptions are unavailable", "error", templatesErr)
+	}
+	byIP := newRateLimiter(subscribeLimitPerIP, subscribeLimitWindow)
+	byAddress := newRateLimiter(subscribeLimitPerAddress, subscribeLimitWindow)
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		if !mailConfig.Enabled() || templatesErr != nil {
+			renderSubscribe(c, http.StatusServiceUnavailable, gd, subscribePage{
+				Title:   "Subscriptions are unavailable",
+				Message: "Email digests are not set up on this site.",
+			})
+			return
+		}
+
+		address, err := netmail.ParseAddress(strings.TrimSpace(c.PostForm("email")))
+		frequency := c.DefaultPostForm("frequency", model.FrequencyDaily)
+		if err != nil || address.Name != "" {
+			renderSubscribe(c, http.StatusBadRequest, gd, subscribePage{
+				Title:    "Get the top products by email",
+				Message:  "Please enter a valid email address.",
+				ShowForm: true,
+			})
+			return
+		}
+		if frequency != model.FrequencyDaily && frequency != model.FrequencyWeekly {
+			renderSubscribe(c, http.StatusBadRequest, gd, subscribePage{
+				Title:    "Get the top products by email",
+				Message:  "Please choose a daily or weekly digest.",
+				ShowForm: true,
+			})
+			return
+		}
+
+		now := time.Now()
+		if !byIP.allow(c.ClientIP(), now) || !byAddress.allow(strings.ToLower(address.Address), now) {
+			renderSubscribe(c, http.StatusTooManyRequests, gd, subscribePage{
+				Title:    "Too many requests",
+				Message:  "Please try again in an hour.",
+				ShowForm: true,
+			})
+			return
+		}
+
+		subscriber, confirm, err := model.Subscribe(db, address.Address, frequency)
+		if err != nil {
+			slog.Error("Error saving subscriber", "error", err)
+			renderSubscribe(c, http.StatusInternalServerError, gd, subscribePage{
+				Title:    "Something went wrong",
+				Message:  "Please try again later.",
+				ShowForm: true,
+			})
+			return
+		}
+
+		if confirm {
+			var msg mail.Message
+			msg, err = templates.ConfirmMessage(*subscriber, gd)
+			if err == nil {
+				err = mailConfig.Send(msg)
+			}
+			if err != nil {
+				slog.Error("Error sending confirmation email", "error", err)
+				// Drop the pending confirmation, so that trying again sends a new one
+				clearErr := db.Model(subscriber).Update("confirm_token", "").Error
+				if clearErr != nil {
+					slog.Error("Error resetting confirmation", "error", clearErr)
+				}
+				renderSubscribe(c, http.StatusInternalServerError, gd, subscribePage{
+					Title:    "Something went wrong",
+					Message:  "We could not send the confirmation email. Please try again later.",
+					ShowForm: true,
+				})
+				return
+			}
+		}
+
+		renderSubscribe(c, http.StatusOK, gd, subscribePage{
+			Title:   "Check your inbox",
+			Message: "If this address is not subscribed yet, you'll get an email with a link to confirm it.",
+		})
+	}
+}
+
+// ConfirmSubscriptionHandler confirms a subscription from the link of the confirmation email.
+func ConfirmSubscriptionHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		subscriber, err := model.ConfirmSubscriber(db, c.Query("token"))
+		if errors.Is(err, model.ErrInvalidToken) {
+			renderSubscribe(c, http.StatusNotFound, gd, subscribePage{
+				Title:   "Link expired",
+				Message: "This confirmation link is invalid or was already used.",
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Error confirming subscriber", "error", err)
+			renderSubscribe(c, http.StatusInternalServerError, gd, subscribePage{
+				Title:   "Something went wrong",
+				Message: "Please try again later.",
+			})
+			return
+		}
+
+		renderSubscribe(c, http.StatusOK, gd, subscribePage{
+			Title:   "You're subscribed",
+			Message: "You'll get the " + subscriber.Frequency + " digest of the top products at " + subscriber.Email + ".",
+		})
+	}
+}
+
+// UnsubscribePageHandler asks to confirm unsubscribing from the link of a digest. The link only
+// shows a button, so that mail scanners following links do not unsubscribe anyone.
+func UnsubscribePageHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		renderSubscribe(c, http.StatusOK, gd, subscribePage{
+			Title:            "Unsubscribe",
+			Message:          "Stop getting the email digest of the top products?",
+			UnsubscribeToken: c.Query("token"),
+		})
+	}
+}
+
+// UnsubscribeHandler ends a subscription. It answers the button of the unsubscribe page and
+// the one-click POST of mail clients (List-Unsubscribe-Post).
+func UnsubscribeHandler(db *gorm.DB, gd types.General) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		db := db.WithContext(c.Request.Context())
+		_, err := model.Unsubscribe(db, c.Query("token"))
+		if errors.Is(err, model.ErrInvalidToken) {
+			renderSubscribe(c, http.StatusNotFound, gd, subscribePage{
+				Title:   "Link expired",
+				Message: "This unsubscribe link is invalid.",
+			})
+			return
+		}
+		if err != nil {
+			slog.Error("Error unsubscribing", "error", err)
+			renderSubscribe(c, http.StatusInternalServerError, gd, subscribePage{
+				Title:   "Something went wrong",
+				Message: "Please try again later.",
+			})
+			return
+		}
+
+		renderSubscribe(c, http.StatusOK, gd, subscribePage{
+			Title:   "You're unsubscribed",
+			Message: "You won't get any more digests.",
+		})
+	}
+}
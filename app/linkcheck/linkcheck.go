@@ -0,0 +1,179 @@
+// Package linkcheck checks whether product websites are still up, politely: each host gets one
+// request at a time with a pause in between, and only a few hosts are checked at once.
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/publicnet"
+	"github.com/dariubs/huntline/app/targeturl"
+)
+
+// Defaults of a Checker.
+const (
+	DefaultConcurrency = 8
+	DefaultHostDelay   = 2 * time.Second
+)
+
+// defaultClient follows redirects like http.DefaultClient but gives up on a site after 15 seconds.
+// It only connects to public addresses and checks every redirect, see publicnet.
+var defaultClient = publicnet.Client(15 * time.Second)
+
+// Checker checks product websites.
+type Checker struct {
+	// Client sends the requests; nil uses a client with a 15 second timeout that only connects to
+	// public addresses
+	Client *http.Client
+	// Concurrency is the number of hosts checked at the same time (default DefaultConcurrency)
+	Concurrency int
+	// HostDelay is the pause between two requests to the same host (default DefaultHostDelay)
+	HostDelay time.Duration
+}
+
+// Check requests a website and classifies the answer. It sends a HEAD request and falls back
+// to GET when the site rejects HEAD with an error status; the body is never read.
+func (c Checker) Check(ctx context.Context, link string) model.LinkCheck {
+	check := model.LinkCheck{URL: link, CheckedAt: time.Now(), Status: model.LinkUnknown}
+	original, err := url.Parse(link)
+	if err != nil {
+		check.Error = err.Error()
+		return check
+	}
+
+	start := time.Now()
+	resp, err := c.request(ctx, http.MethodHead, link)
+	if err == nil && resp.StatusCode >= http.StatusBadRequest {
+		resp.Body.Close()
+		start = time.Now()
+		resp, err = c.request(ctx, http.MethodGet, link)
+	}
+	check.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		check.Status = model.LinkDown
+		check.Error = err.Error()
+		return check
+	}
+	resp.Body.Close()
+
+	check.StatusCode = resp.StatusCode
+	final := resp.Request.URL
+	if final.String() != link {
+		check.Target = final.String()
+	}
+	check.Status = classify(original, final, resp.StatusCode)
+	return check
+}
+
+// request sends a request to a website without reading the response body.
+func (c Checker) request(ctx context.Context, method, link string) (*http.Response, error) {
+	client := c.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, method, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", targeturl.UserAgent)
+	return client.Do(req)
+}
+
+// classify judges a website from the final response to a request for it. Sites that are gone or
+// broken are down; other client errors, such as 403 or 429 from bot protection, say nothing
+// about the site.
+func classify(original, final *url.URL, code int) string {
+	switch {
+	case code == http.StatusNotFound || code == http.StatusGone || code >= http.StatusInternalServerError:
+		return model.LinkDown
+	case code >= http.StatusBadRequest:
+		return model.LinkUnknown
+	case siteHost(final) != siteHost(original):
+		return model.LinkMoved
+	}
+	return model.LinkUp
+}
+
+// siteHost returns the host of a URL without a www. prefix, so that redirecting between the two
+// does not count as moving.
+func siteHost(u *url.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// CheckAll checks the websites and passes each check to record as soon as it is done. The links
+// are visited politely, see EachHost; record is called from several goroutines. It returns early
+// when ctx is canceled.
+func (c Checker) CheckAll(ctx context.Context, links []string, record func(model.LinkCheck)) {
+	EachHost(ctx, links, c.Concurrency, c.HostDelay, func(link string) {
+		record(c.Check(ctx, link))
+	})
+}
+
+// EachHost calls visit for each link, visiting up to concurrency hosts at the same time (default
+// DefaultConcurrency). The links of a host are visited one after the other with delay in between
+// (default DefaultHostDelay). It returns early when ctx is canceled.
+func EachHost(ctx context.Context, links []string, concurrency int, delay time.Duration, visit func(link string)) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	if delay <= 0 {
+		delay = DefaultHostDelay
+	}
+
+	// Group the links by host, keeping the order of the hosts' first links
+	var hosts [][]string
+	index := make(map[string]int)
+	for _, link := range links {
+		host := link
+		if u, err := url.Parse(link); err == nil {
+			host = strings.ToLower(u.Host)
+		}
+		i, ok := index[host]
+		if !ok {
+			i = len(hosts)
+			index[host] = i
+			hosts = append(hosts, nil)
+		}
+		hosts[i] = append(hosts[i], link)
+	}
+
+	queue := make(chan []string)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for hostLinks := range queue {
+				for i, link := range hostLinks {
+					if i > 0 {
+						select {
+						case <-ctx.Done():
+						case <-time.After(delay):
+						}
+					}
+					if ctx.Err() != nil {
+						break
+					}
+					visit(link)
+				}
+			}
+		}()
+	}
+
+	for _, hostLinks := range hosts {
+		select {
+		case <-ctx.Done():
+		case queue <- hostLinks:
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(queue)
+	wg.Wait()
+}
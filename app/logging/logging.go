@@ -0,0 +1,60 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Setup configures the default slog logger from the LOG_LEVEL (debug, info, warn, error; default info)
+// and LOG_FORMAT (text or json; default text) environment variables. Messages written with the
+// standard log package go through the same handler.
+func Setup() error {
+	var level slog.Level
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		err := level.UnmarshalText([]byte(value))
+		if err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL %q: %w", value, err)
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format := strings.ToLower(os.Getenv("LOG_FORMAT")); format {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q: expected text or json", format)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// Fatal logs an error and exits with status 1.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// NewID returns a random identifier for a run or a request.
+func NewID() string {
+	b := make([]byte, 8)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// GormWriter writes GORM's own messages (errors and slow queries) to the default slog logger.
+type GormWriter struct{}
+
+func (GormWriter) Printf(format string, args ...interface{}) {
+	slog.Warn(fmt.Sprintf(format, args...), "component", "gorm")
+}
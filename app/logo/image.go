@@ -0,0 +1,159 @@
+package logo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+
+	_ "golang.org/x/image/bmp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Size is the width and height of the served logos in pixels.
+const Size = 64
+
+// Limits of the images accepted as logos.
+const (
+	maxBytes     = 1 << 20
+	maxDimension = 4096
+	minDimension = 8
+)
+
+// icoMagic starts ICO files, which image.Decode does not know.
+var icoMagic = []byte{0, 0, 1, 0}
+
+// normalize validates an image and returns it as a Size×Size PNG, scaled to fit and centered on
+// a transparent background. PNG, JPEG, GIF, WebP, BMP and ICO images are accepted; SVG is not, as
+// it can carry scripts and would need a rasterizer.
+func normalize(data []byte) ([]byte, error) {
+	if len(data) > maxBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxBytes)
+	}
+
+	var img image.Image
+	var err error
+	if bytes.HasPrefix(data, icoMagic) {
+		img, err = decodeICO(data)
+	} else {
+		contentType := http.DetectContentType(data)
+		switch contentType {
+		case "image/png", "image/jpeg", "image/gif", "image/webp", "image/bmp":
+		default:
+			return nil, fmt.Errorf("unsupported image type %s", contentType)
+		}
+		img, err = decodeChecked(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w < minDimension || h < minDimension {
+		return nil, fmt.Errorf("image is too small (%dx%d)", w, h)
+	}
+
+	// Fit the longer side to Size and center the other
+	dw, dh := Size, Size
+	if w > h {
+		dh = max(1, h*Size/w)
+	} else if h > w {
+		dw = max(1, w*Size/h)
+	}
+	x, y := (Size-dw)/2, (Size-dh)/2
+	dst := image.NewNRGBA(image.Rect(0, 0, Size, Size))
+	draw.Draw(dst, dst.Bounds(), image.Transparent, image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, image.Rect(x, y, x+dw, y+dh), img, b, xdraw.Over, nil)
+
+	var out bytes.Buffer
+	err = png.Encode(&out, dst)
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// decodeChecked decodes an image after checking that its dimensions are reasonable, so that
+// a small file claiming a huge size is not decoded.
+func decodeChecked(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width > maxDimension || cfg.Height > maxDimension {
+		return nil, fmt.Errorf("image is too large (%dx%d)", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// decodeICO decodes the largest image of an ICO file. Its entries are PNG images or BMP images
+// without a file header whose height counts the transparency mask too.
+func decodeICO(data []byte) (image.Image, error) {
+	if len(data) < 6 {
+		return nil, errors.New("truncated ICO file")
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	if count == 0 || len(data) < 6+16*count {
+		return nil, errors.New("truncated ICO directory")
+	}
+
+	var best []byte
+	bestWidth := -1
+	for i := 0; i < count; i++ {
+		entry := data[6+16*i : 6+16*(i+1)]
+		width := int(entry[0])
+		if width == 0 {
+			width = 256
+		}
+		size := int(binary.LittleEndian.Uint32(entry[8:12]))
+		offset := int(binary.LittleEndian.Uint32(entry[12:16]))
+		if offset < 0 || size <= 0 || offset+size > len(data) || offset+size < offset {
+			continue
+		}
+		if width > bestWidth {
+			best, bestWidth = data[offset:offset+size], width
+		}
+	}
+	if best == nil {
+		return nil, errors.New("ICO file has no valid image")
+	}
+
+	if bytes.HasPrefix(best, []byte("\x89PNG")) {
+		return decodeChecked(best)
+	}
+	return decodeChecked(icoBMP(best))
+}
+
+// icoBMP turns the BMP image of an ICO entry into a BMP file: it halves the height, which counts
+// the transparency mask too, and prepends the file header.
+func icoBMP(dib []byte) []byte {
+	if len(dib) < 40 {
+		return nil
+	}
+	headerSize := binary.LittleEndian.Uint32(dib[0:4])
+	bpp := binary.LittleEndian.Uint16(dib[14:16])
+	colors := binary.LittleEndian.Uint32(dib[32:36])
+	if colors == 0 && bpp <= 8 {
+		colors = 1 << bpp
+	}
+
+	fixed := make([]byte, len(dib))
+	copy(fixed, dib)
+	height := int32(binary.LittleEndian.Uint32(fixed[8:12]))
+	binary.LittleEndian.PutUint32(fixed[8:12], uint32(height/2))
+
+	file := make([]byte, 14, 14+len(fixed))
+	file[0], file[1] = 'B', 'M'
+	binary.LittleEndian.PutUint32(file[2:6], uint32(14+len(fixed)))
+	binary.LittleEndian.PutUint32(file[10:14], 14+headerSize+4*colors)
+	return append(file, fixed...)
+}
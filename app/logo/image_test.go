@@ -0,0 +1,150 @@
+package logo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/bmp"
+)
+
+// testImage returns an opaque red image of w×h pixels.
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func encodeBMP(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := bmp.Encode(&buf, img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodeICO wraps images, given as the entry data and width, into an ICO file.
+func encodeICO(entries [][]byte, widths []int) []byte {
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.LittleEndian, []uint16{0, 1, uint16(len(entries))})
+	offset := 6 + 16*len(entries)
+	for i, data := range entries {
+		entry := make([]byte, 16)
+		entry[0] = byte(widths[i])
+		entry[1] = byte(widths[i])
+		binary.LittleEndian.PutUint32(entry[8:12], uint32(len(data)))
+		binary.LittleEndian.PutUint32(entry[12:16], uint32(offset))
+		buf.Write(entry)
+		offset += len(data)
+	}
+	for _, data := range entries {
+		buf.Write(data)
+	}
+	return buf.Bytes()
+}
+
+// icoDIB turns a BMP file into an ICO entry: it drops the file header and doubles the height, as
+// if it counted a transparency mask.
+func icoDIB(file []byte) []byte {
+	dib := append([]byte(nil), file[14:]...)
+	height := binary.LittleEndian.Uint32(dib[8:12])
+	binary.LittleEndian.PutUint32(dib[8:12], height*2)
+	return dib
+}
+
+// decodeLogo decodes a normalized logo and checks its size.
+func decodeLogo(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("normalized logo is not a PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != Size || b.Dy() != Size {
+		t.Fatalf("normalized logo is %dx%d, want %dx%d", b.Dx(), b.Dy(), Size, Size)
+	}
+	return img
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+	}{
+		{"png", func(t *testing.T) []byte { return encodePNG(t, testImage(128, 128)) }},
+		{"bmp", func(t *testing.T) []byte { return encodeBMP(t, testImage(32, 32)) }},
+		{"ico with png", func(t *testing.T) []byte {
+			return encodeICO([][]byte{encodePNG(t, testImage(16, 16)), encodePNG(t, testImage(48, 48))}, []int{16, 48})
+		}},
+		{"ico with bmp", func(t *testing.T) []byte {
+			return encodeICO([][]byte{icoDIB(encodeBMP(t, testImage(32, 32)))}, []int{32})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := normalize(tt.data(t))
+			if err != nil {
+				t.Fatalf("normalize: %v", err)
+			}
+			img := decodeLogo(t, data)
+			if _, _, _, a := img.At(Size/2, Size/2).RGBA(); a == 0 {
+				t.Error("logo is transparent in the middle")
+			}
+		})
+	}
+}
+
+func TestNormalizeCentersWideImages(t *testing.T) {
+	data, err := normalize(encodePNG(t, testImage(64, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := decodeLogo(t, data)
+	if _, _, _, a := img.At(Size/2, 2).RGBA(); a != 0 {
+		t.Error("area above a wide image is not transparent")
+	}
+	if _, _, _, a := img.At(Size/2, Size/2).RGBA(); a == 0 {
+		t.Error("wide image is not centered")
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		data func(t *testing.T) []byte
+		want string
+	}{
+		{"oversize file", func(t *testing.T) []byte { return make([]byte, maxBytes+1) }, "larger than"},
+		{"oversize dimensions", func(t *testing.T) []byte { return encodePNG(t, image.NewGray(image.Rect(0, 0, maxDimension+1, 1))) }, "too large"},
+		{"tiny", func(t *testing.T) []byte { return encodePNG(t, testImage(minDimension-1, minDimension-1)) }, "too small"},
+		{"svg", func(t *testing.T) []byte { return []byte(`<svg xmlns="http://www.w3.org/2000/svg"></svg>`) }, "unsupported"},
+		{"truncated ico", func(t *testing.T) []byte { return []byte{0, 0, 1, 0, 5, 0} }, "truncated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := normalize(tt.data(t))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("normalize error = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
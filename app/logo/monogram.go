@@ -0,0 +1,32 @@
+package logo
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// monogramColors are the backgrounds of monograms, picked by the product's name.
+var monogramColors = []string{"#DC5F00", "#373A40", "#686D76", "#B5451B", "#2F6F73", "#5B4B8A", "#3D6B35", "#8A4B6B"}
+
+// Monogram returns an SVG image of the first letter or digit of name on a colored square,
+// for products without a usable logo.
+func Monogram(name string) []byte {
+	letter := "?"
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			letter = strings.ToUpper(string(r))
+			break
+		}
+	}
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(name)))
+	color := monogramColors[h.Sum32()%uint32(len(monogramColors))]
+
+	return []byte(fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 %[1]d %[1]d">`+
+		`<rect width="%[1]d" height="%[1]d" rx="12" fill="%[2]s"/>`+
+		`<text x="50%%" y="50%%" dy=".35em" text-anchor="middle" fill="#FFFFFF" font-family="Inter, Arial, sans-serif" font-size="32" font-weight="600">%[3]s</text>`+
+		`</svg>`, Size, color, html.EscapeString(letter)))
+}
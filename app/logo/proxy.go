@@ -0,0 +1,175 @@
+// Package logo serves product logos from our own cache: it fetches them from the product
+// websites, validates and resizes them and keeps them on disk or in an S3-compatible bucket, so
+// that visitors never load images from third parties.
+package logo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/dariubs/huntline/app/publicnet"
+	"github.com/dariubs/huntline/app/targeturl"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrNoLogo is returned when none of the sources of a logo has a usable image.
+var ErrNoLogo = errors.New("no usable logo")
+
+// Defaults of a Proxy.
+const (
+	DefaultMaxAge  = 30 * 24 * time.Hour
+	DefaultMissTTL = 24 * time.Hour
+)
+
+// defaultClient gives up on a logo after 5 seconds, as a visitor is waiting for it. It only
+// connects to public addresses, see publicnet.
+var defaultClient = publicnet.Client(5 * time.Second)
+
+// Proxy fetches and caches logos.
+type Proxy struct {
+	Store Store
+	// Client sends the requests; nil uses a client with a 5 second timeout that only connects to
+	// public addresses
+	Client *http.Client
+	// MaxAge is how long a cached logo is served before it is fetched again (default DefaultMaxAge)
+	MaxAge time.Duration
+	// MissTTL is how long a logo without usable sources is not fetched again (default DefaultMissTTL)
+	MissTTL time.Duration
+
+	group singleflight.Group
+}
+
+// NewProxyFromEnv creates a Proxy caching in the store of StoreFromEnv.
+func NewProxyFromEnv() (*Proxy, error) {
+	store, err := StoreFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &Proxy{Store: store}, nil
+}
+
+// Logo returns the PNG logo cached under key, fetching it from the first usable of sources when
+// it is missing or older than MaxAge. It returns ErrNoLogo when no source is usable. Concurrent
+// requests for the same key share a single fetch.
+func (p *Proxy) Logo(ctx context.Context, key string, sources []string) ([]byte, error) {
+	name := cacheName(key)
+	maxAge := p.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	missTTL := p.MissTTL
+	if missTTL <= 0 {
+		missTTL = DefaultMissTTL
+	}
+
+	cached, storedAt, err := p.Store.Get(ctx, name+".png")
+	if err != nil && !errors.Is(err, ErrNotCached) {
+		slog.Warn("Error reading cached logo", "key", key, "error", err)
+	}
+	if err == nil && time.Since(storedAt) < maxAge {
+		return cached, nil
+	}
+	if cached == nil {
+		_, missedAt, err := p.Store.Get(ctx, name+".miss")
+		if err == nil && time.Since(missedAt) < missTTL {
+			return nil, ErrNoLogo
+		}
+	}
+
+	// The fetch outlives a visitor who gives up, so the others waiting for it still get the logo
+	v, err, _ := p.group.Do(name, func() (any, error) {
+		ctx := context.WithoutCancel(ctx)
+		data, err := p.fetchFirst(ctx, sources)
+		if err != nil {
+			err = p.Store.Put(ctx, name+".miss", []byte(time.Now().UTC().Format(time.RFC3339)))
+			if err != nil {
+				slog.Warn("Error caching missing logo", "key", key, "error", err)
+			}
+			return nil, ErrNoLogo
+		}
+		err = p.Store.Put(ctx, name+".png", data)
+		if err != nil {
+			slog.Warn("Error caching logo", "key", key, "error", err)
+		}
+		return data, nil
+	})
+	if err != nil {
+		// A stale logo beats no logo
+		if cached != nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+	return v.([]byte), nil
+}
+
+// fetchFirst returns the first of sources that is a valid image, normalized.
+func (p *Proxy) fetchFirst(ctx context.Context, sources []string) ([]byte, error) {
+	seen := map[string]bool{}
+	for _, source := range sources {
+		if source == "" || seen[source] {
+			continue
+		}
+		seen[source] = true
+		data, err := p.fetch(ctx, source)
+		if err == nil {
+			data, err = normalize(data)
+		}
+		if err != nil {
+			slog.Debug("Unusable logo source", "url", source, "error", err)
+			continue
+		}
+		return data, nil
+	}
+	return nil, ErrNoLogo
+}
+
+// fetch downloads an image of at most maxBytes.
+func (p *Proxy) fetch(ctx context.Context, source string) ([]byte, error) {
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported scheme %q", req.URL.Scheme)
+	}
+	req.Header.Set("User-Agent", targeturl.UserAgent)
+	req.Header.Set("Accept", "image/png,image/webp,image/jpeg,image/gif,image/x-icon,image/*;q=0.8")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxBytes)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxBytes)
+	}
+	return data, nil
+}
+
+// cacheName turns a key, e.g. a domain key with slashes, into a safe file and object name.
+func cacheName(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:16])
+}
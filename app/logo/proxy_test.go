@@ -0,0 +1,141 @@
+package logo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// logoServer serves a PNG at /icon.png and 404 elsewhere, counting the requests.
+func logoServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	icon := encodePNG(t, testImage(32, 32))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/icon.png" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(icon)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// age moves the modification time of a cached entry of key back by d.
+func age(t *testing.T, store DiskStore, key, suffix string, d time.Duration) {
+	t.Helper()
+	past := time.Now().Add(-d)
+	err := os.Chtimes(filepath.Join(store.Dir, cacheName(key)+suffix), past, past)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestProxyLogo(t *testing.T) {
+	ctx := context.Background()
+	server, requests := logoServer(t)
+	store := DiskStore{Dir: t.TempDir()}
+	proxy := &Proxy{Store: store, Client: server.Client()}
+
+	// The first usable source wins
+	data, err := proxy.Logo(ctx, "example.com", []string{server.URL + "/missing.ico", server.URL + "/icon.png"})
+	if err != nil {
+		t.Fatalf("Logo: %v", err)
+	}
+	decodeLogo(t, data)
+	if n := requests.Load(); n != 2 {
+		t.Errorf("fetched %d sources, want 2", n)
+	}
+
+	// A fresh logo is served from the cache
+	cached, err := proxy.Logo(ctx, "example.com", []string{server.URL + "/icon.png"})
+	if err != nil {
+		t.Fatalf("Logo from the cache: %v", err)
+	}
+	if !bytes.Equal(cached, data) {
+		t.Error("cached logo differs from the fetched one")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("cached logo was fetched again (%d requests)", n)
+	}
+}
+
+func TestProxyLogoMiss(t *testing.T) {
+	ctx := context.Background()
+	server, requests := logoServer(t)
+	store := DiskStore{Dir: t.TempDir()}
+	proxy := &Proxy{Store: store, Client: server.Client()}
+	sources := []string{server.URL + "/missing.ico"}
+
+	_, err := proxy.Logo(ctx, "example.com", sources)
+	if !errors.Is(err, ErrNoLogo) {
+		t.Fatalf("Logo without usable sources = %v, want ErrNoLogo", err)
+	}
+
+	// The miss is remembered for MissTTL
+	_, err = proxy.Logo(ctx, "example.com", sources)
+	if !errors.Is(err, ErrNoLogo) {
+		t.Fatalf("Logo after a miss = %v, want ErrNoLogo", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("remembered miss was fetched again (%d requests)", n)
+	}
+
+	// and retried after it
+	age(t, store, "example.com", ".miss", DefaultMissTTL+time.Minute)
+	_, err = proxy.Logo(ctx, "example.com", []string{server.URL + "/icon.png"})
+	if err != nil {
+		t.Fatalf("Logo after the miss expired: %v", err)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expired miss made %d requests, want 2", n)
+	}
+}
+
+func TestProxyLogoStale(t *testing.T) {
+	ctx := context.Background()
+	server, requests := logoServer(t)
+	store := DiskStore{Dir: t.TempDir()}
+	proxy := &Proxy{Store: store, Client: server.Client()}
+
+	data, err := proxy.Logo(ctx, "example.com", []string{server.URL + "/icon.png"})
+	if err != nil {
+		t.Fatalf("Logo: %v", err)
+	}
+
+	// An expired logo is fetched again, and served stale when its sources are gone
+	age(t, store, "example.com", ".png", DefaultMaxAge+time.Minute)
+	stale, err := proxy.Logo(ctx, "example.com", []string{server.URL + "/gone.png"})
+	if err != nil {
+		t.Fatalf("Logo with a stale cache: %v", err)
+	}
+	if !bytes.Equal(stale, data) {
+		t.Error("stale logo differs from the cached one")
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("expired logo made %d requests, want 2", n)
+	}
+}
+
+func TestProxyRefusesLocalAddresses(t *testing.T) {
+	server, requests := logoServer(t)
+	proxy := &Proxy{Store: DiskStore{Dir: t.TempDir()}}
+
+	_, err := proxy.Logo(context.Background(), "example.com", []string{server.URL + "/icon.png"})
+	if !errors.Is(err, ErrNoLogo) {
+		t.Errorf("Logo from a loopback address = %v, want ErrNoLogo", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("loopback server got %d requests", n)
+	}
+}
@@ -0,0 +1,168 @@
+package logo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// ErrNotCached is returned by a Store for a key it does not hold.
+var ErrNotCached = errors.New("not cached")
+
+// Store caches logos by key.
+type Store interface {
+	// Get returns the data stored under key and when it was stored, or ErrNotCached
+	Get(ctx context.Context, key string) ([]byte, time.Time, error)
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// DiskStore caches logos as files of a directory.
+type DiskStore struct {
+	Dir string
+}
+
+// Get reads the file of key.
+func (s DiskStore) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	path := filepath.Join(s.Dir, key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, ErrNotCached
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.ModTime(), nil
+}
+
+// Put writes the file of key, replacing it atomically.
+func (s DiskStore) Put(ctx context.Context, key string, data []byte) error {
+	err := os.MkdirAll(s.Dir, 0o755)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	closeErr := tmp.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.Dir, key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// S3Store caches logos as objects of an S3-compatible bucket, e.g. AWS S3, Cloudflare R2 or MinIO.
+type S3Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// S3Config locates the bucket of an S3Store.
+type S3Config struct {
+	// Endpoint is the host[:port] of the S3 API, e.g. s3.amazonaws.com or localhost:9000
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	// Prefix is prepended to the object keys, e.g. "logos/"
+	Prefix string
+	// Insecure uses plain HTTP, e.g. for a local stand-in
+	Insecure bool
+}
+
+// NewS3Store connects to the bucket of cfg.
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("the S3 endpoint and bucket are required")
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: !cfg.Insecure,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket, prefix: cfg.Prefix}, nil
+}
+
+// Get downloads the object of key.
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.prefix+key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer obj.Close()
+
+	info, err := obj.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, time.Time{}, ErrNotCached
+		}
+		return nil, time.Time{}, err
+	}
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return data, info.LastModified, nil
+}
+
+// Put uploads the object of key.
+func (s *S3Store) Put(ctx context.Context, key string, data []byte) error {
+	contentType := "application/octet-stream"
+	if strings.HasSuffix(key, ".png") {
+		contentType = "image/png"
+	}
+	_, err := s.client.PutObject(ctx, s.bucket, s.prefix+key, bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: contentType})
+	return err
+}
+
+// StoreFromEnv creates the store of LOGO_STORE: "disk" (the default) caches in LOGO_CACHE_DIR
+// (default cache/logos), "s3" in the bucket of LOGO_S3_ENDPOINT, LOGO_S3_BUCKET, LOGO_S3_REGION,
+// LOGO_S3_ACCESS_KEY, LOGO_S3_SECRET_KEY and LOGO_S3_PREFIX; LOGO_S3_INSECURE=true uses plain HTTP.
+func StoreFromEnv() (Store, error) {
+	switch kind := strings.ToLower(os.Getenv("LOGO_STORE")); kind {
+	case "", "disk":
+		dir := os.Getenv("LOGO_CACHE_DIR")
+		if dir == "" {
+			dir = filepath.Join("cache", "logos")
+		}
+		return DiskStore{Dir: dir}, nil
+	case "s3":
+		return NewS3Store(S3Config{
+			Endpoint:  os.Getenv("LOGO_S3_ENDPOINT"),
+			Bucket:    os.Getenv("LOGO_S3_BUCKET"),
+			Region:    os.Getenv("LOGO_S3_REGION"),
+			AccessKey: os.Getenv("LOGO_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("LOGO_S3_SECRET_KEY"),
+			Prefix:    os.Getenv("LOGO_S3_PREFIX"),
+			Insecure:  os.Getenv("LOGO_S3_INSECURE") == "true",
+		})
+	default:
+		return nil, fmt.Errorf("invalid LOGO_STORE %q, expected disk or s3", kind)
+	}
+}
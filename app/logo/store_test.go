@@ -0,0 +1,51 @@
+package logo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDiskStore(t *testing.T) {
+	ctx := context.Background()
+	// The directory is created on the first Put
+	store := DiskStore{Dir: filepath.Join(t.TempDir(), "logos")}
+
+	_, _, err := store.Get(ctx, "a.png")
+	if !errors.Is(err, ErrNotCached) {
+		t.Fatalf("Get of a missing key = %v, want ErrNotCached", err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	err = store.Put(ctx, "a.png", []byte("first"))
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	err = store.Put(ctx, "a.png", []byte("second"))
+	if err != nil {
+		t.Fatalf("Put over an existing key: %v", err)
+	}
+
+	data, storedAt, err := store.Get(ctx, "a.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(data) != "second" {
+		t.Errorf("Get = %q, want the last data put", data)
+	}
+	if storedAt.Before(before) {
+		t.Errorf("stored at %v, before the Put at %v", storedAt, before)
+	}
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(store.Dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("cache directory holds %d files, want 1", len(entries))
+	}
+}
@@ -0,0 +1,129 @@
+package mail
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+)
+
+// smtpStandIn accepts one SMTP session on a local port and records the envelope and data.
+type smtpStandIn struct {
+	addr string
+	from string
+	to   []string
+	data string
+	done chan struct{}
+}
+
+func startSMTP(t *testing.T) *smtpStandIn {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	s := &smtpStandIn{addr: ln.Addr().String(), done: make(chan struct{})}
+	go func() {
+		defer close(s.done)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 stand-in ESMTP")
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch verb {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 stand-in")
+			case "MAIL":
+				s.from = strings.TrimSuffix(strings.TrimPrefix(line, "MAIL FROM:<"), ">")
+				tp.PrintfLine("250 OK")
+			case "RCPT":
+				s.to = append(s.to, strings.TrimSuffix(strings.TrimPrefix(line, "RCPT TO:<"), ">"))
+				tp.PrintfLine("250 OK")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				lines, err := tp.ReadDotLines()
+				if err != nil {
+					return
+				}
+				s.data = strings.Join(lines, "\n")
+				tp.PrintfLine("250 OK")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				return
+			default:
+				tp.PrintfLine("502 not implemented")
+			}
+		}
+	}()
+	return s
+}
+
+func TestSend(t *testing.T) {
+	s := startSMTP(t)
+	host, port, _ := net.SplitHostPort(s.addr)
+	config := Config{Host: host, Port: port, From: "digest@example.com"}
+
+	err := config.Send(Message{
+		To:      []string{"reader@example.com"},
+		Subject: "Top products – today",
+		Text:    "Plain body",
+		HTML:    "<p>HTML body</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe?token=t>"},
+	})
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	<-s.done
+
+	if s.from != "digest@example.com" {
+		t.Errorf("MAIL FROM = %q", s.from)
+	}
+	if len(s.to) != 1 || s.to[0] != "reader@example.com" {
+		t.Errorf("RCPT TO = %q", s.to)
+	}
+	for _, want := range []string{
+		"To: reader@example.com",
+		"Subject: =?utf-8?q?Top_products_=E2=80=93_today?=",
+		"List-Unsubscribe: <https://example.com/unsubscribe?token=t>",
+		"Content-Type: multipart/alternative; boundary=",
+		"Plain body",
+		"<p>HTML body</p>",
+	} {
+		if !strings.Contains(s.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, s.data)
+		}
+	}
+}
+
+func TestSendNotConfigured(t *testing.T) {
+	err := Config{}.Send(Message{To: []string{"reader@example.com"}})
+	if err == nil {
+		t.Fatal("Send without SMTP_HOST succeeded")
+	}
+}
+
+func TestPlainTextMessage(t *testing.T) {
+	msg := Message{To: []string{"a@example.com"}, Subject: "Hi", Text: "line one\nline two"}
+	r := textproto.NewReader(bufio.NewReader(strings.NewReader(string(msg.bytes("from@example.com")))))
+	header, err := r.ReadMIMEHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := header.Get("From"); got != "from@example.com" {
+		t.Errorf("From = %q", got)
+	}
+}
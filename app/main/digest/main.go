@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/envfile"
+	"github.com/dariubs/huntline/app/digest"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/mail"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+)
+
+// emailTemplates is the directory of the digest email templates.
+const emailTemplates = "view/email"
+
+// dueSince returns the time before which a subscriber's last digest of the frequency must have been
+// sent for the subscriber to get the next one: the start of today (Pacific Time) for daily digests
+// and six days ago for weekly digests, so a weekly run a little earlier than last week still sends.
+func dueSince(frequency string, now time.Time) time.Time {
+	loc := types.SanFranciscoLocation()
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if frequency == model.FrequencyWeekly {
+		return today.AddDate(0, 0, -6)
+	}
+	return today
+}
+
+func main() {
+	frequency := flag.String("frequency", model.FrequencyDaily, "Digest to send: daily or weekly (default daily)")
+	limit := flag.Int("limit", digest.DefaultLimit, "Number of products per platform (default 10)")
+	dryRun := flag.Bool("dry-run", false, "If set, print the plain text digest and the recipients instead of sending it")
+	preview := flag.String("preview", "", "Send the digest only to this address, without recording it as sent")
+	flag.Parse()
+
+	err := envfile.Load()
+	if err != nil {
+		logging.Fatal("Error loading .env file", "error", err)
+	}
+
+	err = logging.Setup()
+	if err != nil {
+		logging.Fatal("Invalid logging configuration", "error", err)
+	}
+
+	if *frequency != model.FrequencyDaily && *frequency != model.FrequencyWeekly {
+		logging.Fatal("Invalid -frequency: expected daily or weekly")
+	}
+
+	dbConfig, err := db.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid database configuration", "error", err)
+	}
+	dbs, err := db.Open(dbConfig)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+
+	gd := types.General{
+		Name: os.Getenv("HL_NAME"),
+		URL:  os.Getenv("HL_URL"),
+	}
+	mailConfig := mail.ConfigFromEnv()
+	if !*dryRun && !mailConfig.Enabled() {
+		logging.Fatal("SMTP_HOST and SMTP_FROM are required to send digests")
+	}
+
+	templates, err := digest.LoadTemplates(emailTemplates)
+	if err != nil {
+		logging.Fatal("Error loading email templates", "error", err)
+	}
+
+	now := time.Now()
+	d, err := digest.Build(dbs, *frequency, now, *limit)
+	if err != nil {
+		logging.Fatal("Error building digest", "error", err)
+	}
+	logger := slog.With("frequency", *frequency, "period", d.Period())
+	if d.Empty() {
+		logger.Info("No rankings to send")
+		return
+	}
+
+	var subscribers []model.Subscriber
+	if *preview != "" {
+		subscribers = []model.Subscriber{{Email: *preview, Frequency: *frequency, UnsubscribeToken: "preview"}}
+	} else {
+		subscribers, err = model.DueSubscribers(dbs, *frequency, dueSince(*frequency, now))
+		if err != nil {
+			logging.Fatal("Error loading subscribers", "error", err)
+		}
+	}
+
+	sent, failed := 0, 0
+	for _, subscriber := range subscribers {
+		msg, err := templates.DigestMessage(d, subscriber, gd)
+		if err != nil {
+			logging.Fatal("Error rendering digest", "error", err)
+		}
+
+		if *dryRun {
+			fmt.Printf("To: %s\nSubject: %s\n\n%s\n", subscriber.Email, msg.Subject, msg.Text)
+			continue
+		}
+
+		err = mailConfig.Send(msg)
+		if err != nil {
+			logger.Error("Error sending digest", "subscriber", subscriber.ID, "error", err)
+			failed++
+			continue
+		}
+		if *preview == "" {
+			err = subscriber.MarkSent(dbs, time.Now())
+			if err != nil {
+				logger.Error("Error recording sent digest", "subscriber", subscriber.ID, "error", err)
+			}
+		}
+		sent++
+	}
+
+	logger.Info("Finished sending digest", "subscribers", len(subscribers), "sent", sent, "failed", failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -1,30 +1,79 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"html/template"
+	"log/slog"
 	"os"
+	"strings"
 
 	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/envfile"
+	"github.com/dariubs/huntline/app/freshness"
 	"github.com/dariubs/huntline/app/handler/huntline"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/logo"
+	"github.com/dariubs/huntline/app/metrics"
+	"github.com/dariubs/huntline/app/tracing"
 	"github.com/dariubs/huntline/app/types"
 	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
 	"gorm.io/gorm"
 )
 
+// serviceName identifies the web server in traces.
+const serviceName = "huntline-web"
+
 var dbs *gorm.DB
 var gd types.General
 
 func main() {
-	err := godotenv.Load()
+	err := envfile.Load()
+	if err != nil {
+		logging.Fatal("Error loading .env file", "error", err)
+	}
+
+	err = logging.Setup()
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		logging.Fatal("Invalid logging configuration", "error", err)
 	}
 
-	dbs, err = db.ConnectToDB()
+	_, err = tracing.Setup(serviceName)
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Invalid tracing configuration", "error", err)
+	}
+
+	dbConfig, err := db.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid database configuration", "error", err)
+	}
+	dbs, err = db.Open(dbConfig)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+
+	err = metrics.InstrumentGORM(dbs)
+	if err != nil {
+		logging.Fatal("Failed to instrument database", "error", err)
+	}
+	err = tracing.InstrumentGORM(dbs)
+	if err != nil {
+		logging.Fatal("Failed to instrument database", "error", err)
+	}
+	err = metrics.RegisterFreshness(dbs)
+	if err != nil {
+		logging.Fatal("Failed to register metrics", "error", err)
+	}
+
+	monitor, err := freshness.NewMonitorFromEnv(dbs)
+	if err != nil {
+		logging.Fatal("Invalid freshness configuration", "error", err)
+	}
+	go monitor.Run(context.Background())
+
+	logos, err := logo.NewProxyFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid logo configuration", "error", err)
 	}
 
 	gd = types.General{
@@ -37,12 +86,27 @@ func main() {
 		GitHub:  os.Getenv("HL_GITHUB"),
 	}
 
-	router := gin.Default()
-	router.Use(gin.Logger())
+	// gin.New instead of gin.Default: requests are logged once, through slog
+	router := gin.New()
+	router.Use(tracing.GinMiddleware(serviceName))
+	router.Use(logging.GinMiddleware())
+	router.Use(gin.Recovery())
+	router.Use(metrics.GinMiddleware())
 	router.Delims("{{", "}}")
 
+	// Client addresses, used for click counting and rate limits, are only taken from the
+	// X-Forwarded-For header of the proxies in TRUSTED_PROXIES
+	err = router.SetTrustedProxies(trustedProxies())
+	if err != nil {
+		logging.Fatal("Invalid TRUSTED_PROXIES", "error", err)
+	}
+
 	router.Static("/assets", "./assets")
 
+	// staleData feeds the stale data banner of the pages
+	router.SetFuncMap(template.FuncMap{
+		"staleData": monitor.Problems,
+	})
 	router.LoadHTMLGlob("view/huntline/**/*")
 
 	router.GET("/", huntline.IndexHandler(dbs, gd))
@@ -50,12 +114,39 @@ func main() {
 	router.GET("/archive", huntline.ArchiveHandler(dbs, gd))
 	router.GET("/best/month", huntline.BestMonthHandler(dbs, gd))
 	router.GET("/best/week", huntline.BestWeekHandler(dbs, gd))
+	router.GET("/best/clicks", huntline.MostClickedHandler(dbs, gd))
 	router.GET("/platforms", huntline.PlatformsHandler(dbs, gd))
+	router.GET("/product/:id", huntline.ProductHandler(dbs, gd))
+	router.GET("/logo/:id", huntline.LogoHandler(dbs, gd, logos))
+	router.GET("/go/:id", huntline.GoHandler(dbs, gd))
+	router.GET("/subscribe", huntline.SubscribePageHandler(dbs, gd))
+	router.POST("/subscribe", huntline.SubscribeHandler(dbs, gd))
+	router.GET("/subscribe/confirm", huntline.ConfirmSubscriptionHandler(dbs, gd))
+	router.GET("/unsubscribe", huntline.UnsubscribePageHandler(dbs, gd))
+	router.POST("/unsubscribe", huntline.UnsubscribeHandler(dbs, gd))
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	port := os.Getenv("HL_PORT")
 	if port == "" {
 		port = "8080"
 	}
-	router.Run(fmt.Sprintf("0.0.0.0:%s", port))
+	addr := fmt.Sprintf("0.0.0.0:%s", port)
+	slog.Info("Starting web server", "addr", addr)
+	err = router.Run(addr)
+	if err != nil {
+		logging.Fatal("Web server stopped", "error", err)
+	}
+}
 
+// trustedProxies returns the comma-separated addresses and CIDR ranges of TRUSTED_PROXIES, or nil
+// to trust no proxy and use the address of the connection.
+func trustedProxies() []string {
+	var proxies []string
+	for _, proxy := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
 }
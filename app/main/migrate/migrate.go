@@ -1,20 +1,120 @@
 package main
 
 import (
-	"log"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
 
-	"github.com/dariubs/huntline/app/model"
-	"github.com/joho/godotenv"
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/envfile"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/migrations"
 )
 
+const usage = `Usage: migrate [command] [flags]
+
+Commands:
+  up      Apply the pending migrations (the default)
+  down    Revert the last applied migrations
+  status  List the migrations and when they were applied
+  redo    Revert the last applied migration and apply it again
+`
+
 func main() {
-	err := godotenv.Load()
+	err := envfile.Load()
+	if err != nil {
+		logging.Fatal("Error loading .env file", "error", err)
+	}
+
+	err = logging.Setup()
 	if err != nil {
-		log.Fatal("Error loading .env file")
+		logging.Fatal("Invalid logging configuration", "error", err)
+	}
+
+	command := "up"
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
+	}
+
+	fs := flag.NewFlagSet(command, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
 	}
+	to := fs.Int("to", 0, "up: apply the migrations up to and including this version (default 0, all)")
+	steps := fs.Int("steps", 1, "down: number of migrations to revert")
+	fs.Parse(args)
 
-	err = model.AutoMigrate()
+	dbConfig, err := db.ConfigFromEnv()
 	if err != nil {
-		log.Fatal(err)
+		logging.Fatal("Invalid database configuration", "error", err)
+	}
+	dbs, err := db.Open(dbConfig)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+	sqlDB, err := dbs.DB()
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+	migrator, err := migrations.New(sqlDB, dbs.Dialector.Name())
+	if err != nil {
+		logging.Fatal("Invalid migrations", "error", err)
+	}
+	migrator.Log = func(action string, m migrations.Migration) {
+		slog.Info("Migration "+action, "version", m.Version, "name", m.Name)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, *to)
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		slog.Info("Migrations applied", "count", applied)
+	case "down":
+		if *steps < 1 {
+			logging.Fatal("Invalid -steps, expected at least 1", "steps", *steps)
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		slog.Info("Migrations reverted", "count", reverted)
+	case "redo":
+		redone, err := migrator.Redo(ctx)
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		if !redone {
+			slog.Info("No migration applied, nothing to redo")
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logging.Fatal("Failed to read migration status", "error", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+				if s.Up == "" {
+					applied += " (unknown to this binary)"
+				}
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, applied)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", command)
+		fs.Usage()
+		os.Exit(2)
 	}
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/platform"
+)
+
+// Kinds of change reported by a dry run.
+const (
+	changeNew     = "new"
+	changeRemoved = "removed"
+	changeRank    = "rank"
+	changeTagline = "tagline"
+	changeURL     = "url"
+)
+
+// change is a single difference between the stored and the fetched rankings of a day.
+type change struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	Old  string `json:"old,omitempty"`
+	New  string `json:"new,omitempty"`
+}
+
+// dateDiff lists the changes a run would apply to a platform's day.
+type dateDiff struct {
+	Platform string   `json:"platform"`
+	Date     string   `json:"date"`
+	Changes  []change `json:"changes"`
+}
+
+// diffProducts compares the stored products of a day with freshly fetched ones.
+// Fetched products are reported in rank order, followed by the stored products that would be dropped.
+func diffProducts(existing []model.Product, fetched []platform.Product) []change {
+	stored := make(map[string]model.Product)
+	for _, product := range existing {
+		if product.DroppedAt == nil {
+			stored[product.Name] = product
+		}
+	}
+
+	changes := []change{}
+	fetchedNames := make(map[string]bool)
+	for _, product := range fetched {
+		fetchedNames[product.Name] = true
+
+		old, ok := stored[product.Name]
+		if !ok {
+			changes = append(changes, change{Kind: changeNew, Name: product.Name, New: fmt.Sprintf("#%d", product.Rank)})
+			continue
+		}
+		if old.Rank != product.Rank {
+			changes = append(changes, change{Kind: changeRank, Name: product.Name, Old: fmt.Sprintf("#%d", old.Rank), New: fmt.Sprintf("#%d", product.Rank)})
+		}
+		if old.Tagline != product.Tagline {
+			changes = append(changes, change{Kind: changeTagline, Name: product.Name, Old: old.Tagline, New: product.Tagline})
+		}
+		// Compare the platform's links; rows stored before canonicalization only have URL
+		oldURL := old.RawURL
+		if oldURL == "" {
+			oldURL = old.URL
+		}
+		if oldURL != product.URL {
+			changes = append(changes, change{Kind: changeURL, Name: product.Name, Old: oldURL, New: product.URL})
+		}
+	}
+
+	for _, product := range existing {
+		if product.DroppedAt == nil && !fetchedNames[product.Name] {
+			changes = append(changes, change{Kind: changeRemoved, Name: product.Name, Old: fmt.Sprintf("#%d", product.Rank)})
+		}
+	}
+	return changes
+}
+
+// printDiff writes a day's diff to w as text or as a single JSON line.
+func printDiff(w io.Writer, diff dateDiff, format string) {
+	if format == "json" {
+		err := json.NewEncoder(w).Encode(diff)
+		if err != nil {
+			slog.Error("Error encoding diff", "platform", diff.Platform, "date", diff.Date, "error", err)
+		}
+		return
+	}
+
+	fmt.Fprintf(w, "Changes for %s on %s:\n", diff.Platform, diff.Date)
+	if len(diff.Changes) == 0 {
+		fmt.Fprintf(w, "  no changes\n\n")
+		return
+	}
+	for _, c := range diff.Changes {
+		switch c.Kind {
+		case changeNew:
+			fmt.Fprintf(w, "  + %s (%s)\n", c.Name, c.New)
+		case changeRemoved:
+			fmt.Fprintf(w, "  - %s (was %s)\n", c.Name, c.Old)
+		default:
+			fmt.Fprintf(w, "  ~ %s %s: %q -> %q\n", c.Name, c.Kind, c.Old, c.New)
+		}
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/platform"
+)
+
+// testDiff is the diff of a day whose stored rankings are re-fetched: beta and alpha swap places,
+// alpha's tagline and beta's link change, gamma and epsilon are unchanged, zeta is new and eta
+// falls out of the top list. delta was dropped by an earlier fetch.
+func testDiff() dateDiff {
+	droppedAt := time.Now()
+	existing := []model.Product{
+		{Name: "alpha", Tagline: "First", RawURL: "https://www.producthunt.com/r/alpha", URL: "https://alpha.example.com/", Rank: 1},
+		{Name: "beta", RawURL: "https://www.producthunt.com/r/beta", Rank: 2},
+		{Name: "gamma", RawURL: "https://www.producthunt.com/r/gamma", Rank: 3},
+		{Name: "delta", RawURL: "https://www.producthunt.com/r/delta", DroppedAt: &droppedAt, DroppedRank: 3},
+		{Name: "eta", RawURL: "https://www.producthunt.com/r/eta", Rank: 4},
+		// Stored before links were canonicalized
+		{Name: "epsilon", URL: "https://www.producthunt.com/r/epsilon", Rank: 5},
+	}
+	fetched := []platform.Product{
+		{Name: "beta", URL: "https://www.producthunt.com/r/beta2", Rank: 1},
+		{Name: "alpha", Tagline: "The first", URL: "https://www.producthunt.com/r/alpha", Rank: 2},
+		{Name: "gamma", URL: "https://www.producthunt.com/r/gamma", Rank: 3},
+		{Name: "zeta", URL: "https://www.producthunt.com/r/zeta", Rank: 4},
+		{Name: "epsilon", URL: "https://www.producthunt.com/r/epsilon", Rank: 5},
+	}
+	return dateDiff{Platform: "producthunt", Date: "2025-01-15", Changes: diffProducts(existing, fetched)}
+}
+
+func TestDiffProductsText(t *testing.T) {
+	var out strings.Builder
+	printDiff(&out, testDiff(), "text")
+	want := `Changes for producthunt on 2025-01-15:
+  ~ beta rank: "#2" -> "#1"
+  ~ beta url: "https://www.producthunt.com/r/beta" -> "https://www.producthunt.com/r/beta2"
+  ~ alpha rank: "#1" -> "#2"
+  ~ alpha tagline: "First" -> "The first"
+  + zeta (#4)
+  - eta (was #4)
+
+`
+	if out.String() != want {
+		t.Errorf("text diff:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDiffProductsJSON(t *testing.T) {
+	var out strings.Builder
+	printDiff(&out, testDiff(), "json")
+	want := `{"platform":"producthunt","date":"2025-01-15","changes":[` +
+		`{"kind":"rank","name":"beta","old":"#2","new":"#1"},` +
+		`{"kind":"url","name":"beta","old":"https://www.producthunt.com/r/beta","new":"https://www.producthunt.com/r/beta2"},` +
+		`{"kind":"rank","name":"alpha","old":"#1","new":"#2"},` +
+		`{"kind":"tagline","name":"alpha","old":"First","new":"The first"},` +
+		`{"kind":"new","name":"zeta","new":"#4"},` +
+		`{"kind":"removed","name":"eta","old":"#4"}]}` + "\n"
+	if out.String() != want {
+		t.Errorf("JSON diff:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestDiffProductsUnchanged(t *testing.T) {
+	existing := []model.Product{{Name: "alpha", RawURL: "https://www.producthunt.com/r/alpha", Rank: 1}}
+	fetched := []platform.Product{{Name: "alpha", URL: "https://www.producthunt.com/r/alpha", Rank: 1}}
+	diff := dateDiff{Platform: "producthunt", Date: "2025-01-15", Changes: diffProducts(existing, fetched)}
+
+	var text strings.Builder
+	printDiff(&text, diff, "text")
+	if want := "Changes for producthunt on 2025-01-15:\n  no changes\n\n"; text.String() != want {
+		t.Errorf("text diff = %q, want %q", text.String(), want)
+	}
+	// An empty diff is an empty list in JSON, not null
+	var jsonOut strings.Builder
+	printDiff(&jsonOut, diff, "json")
+	if want := `{"platform":"producthunt","date":"2025-01-15","changes":[]}` + "\n"; jsonOut.String() != want {
+		t.Errorf("JSON diff = %q, want %q", jsonOut.String(), want)
+	}
+}
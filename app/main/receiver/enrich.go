@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"time"
+
+	"github.com/dariubs/huntline/app/enrich"
+	"github.com/dariubs/huntline/app/linkcheck"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// enrichOptions configures a run of the metadata enrichment.
+type enrichOptions struct {
+	// MaxAge is how long a website's metadata stays fresh
+	MaxAge time.Duration
+	// Limit is the maximum number of websites fetched per run (0 fetches all that are due)
+	Limit   int
+	Fetcher enrich.Fetcher
+}
+
+// defaultEnrichOptions are the options of the scheduled enrich job.
+var defaultEnrichOptions = enrichOptions{MaxAge: 30 * 24 * time.Hour}
+
+// enrichProducts fetches the metadata of the product websites last fetched more than MaxAge ago
+// and stores it. It returns the number of websites fetched and of failed fetches.
+func enrichProducts(ctx context.Context, logger *slog.Logger, opts enrichOptions) (fetched, failed int, err error) {
+	ctx, span := tracing.Start(ctx, "receiver.enrichProducts")
+	links, err := model.MetadataToFetch(dbs.WithContext(ctx), time.Now().Add(-opts.MaxAge), opts.Limit)
+	if err != nil {
+		tracing.End(span, err)
+		return 0, 0, err
+	}
+	span.SetAttributes(attribute.Int("links", len(links)))
+	logger.Info("Fetching website metadata", "count", len(links))
+
+	results := make(chan model.SiteMetadata)
+	done := make(chan struct{})
+	go func() {
+		// Record from a single goroutine so the counts need no locking
+		for md := range results {
+			fetched++
+			if md.Error != "" {
+				failed++
+				logger.Debug("Error fetching website metadata", "url", md.URL, "error", md.Error)
+			}
+			err := model.SaveSiteMetadata(dbs.WithContext(ctx), &md)
+			if err != nil {
+				logger.Warn("Error saving website metadata", "url", md.URL, "error", err)
+			}
+		}
+		close(done)
+	}()
+	opts.Fetcher.FetchAll(ctx, links, func(md model.SiteMetadata) {
+		results <- md
+	})
+	close(results)
+	<-done
+
+	tracing.End(span, nil)
+	return fetched, failed, nil
+}
+
+// runEnrichJob runs the metadata enrichment and logs the outcome.
+func runEnrichJob(logger *slog.Logger, opts enrichOptions) {
+	fetched, failed, err := enrichProducts(context.Background(), logger, opts)
+	if err != nil {
+		logger.Error("Enrichment failed", "error", err)
+		return
+	}
+	logger.Info("Enrichment finished", "fetched", fetched, "failed", failed)
+}
+
+// runEnrich implements the "enrich" subcommand. It fetches the website metadata that is due once.
+func runEnrich(args []string) {
+	fs := flag.NewFlagSet("enrich", flag.ExitOnError)
+	maxAge := fs.Duration("max-age", defaultEnrichOptions.MaxAge, "Fetch websites whose metadata is older than this (default 720h)")
+	limit := fs.Int("limit", 0, "Maximum number of websites to fetch (default 0, all that are due)")
+	concurrency := fs.Int("concurrency", linkcheck.DefaultConcurrency, "Number of hosts fetched at the same time (default 8)")
+	hostDelay := fs.Duration("host-delay", linkcheck.DefaultHostDelay, "Pause between two requests to the same host (default 2s)")
+	fs.Parse(args)
+
+	connect()
+	logger := slog.With("job", jobEnrich, "run", logging.NewID())
+	runEnrichJob(logger, enrichOptions{
+		MaxAge:  *maxAge,
+		Limit:   *limit,
+		Fetcher: enrich.Fetcher{Concurrency: *concurrency, HostDelay: *hostDelay},
+	})
+	err := flushTraces(context.Background())
+	if err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
+}
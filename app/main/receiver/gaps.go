@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/model"
+)
+
+// gap is a date with no stored rankings or fewer products than the platform's depth.
+type gap struct {
+	Date  string
+	Count int64
+}
+
+// findGaps scans the stored rankings of a platform between from and to (inclusive, YYYY-MM-DD)
+// and returns the dates that are missing or have fewer than depth products.
+func findGaps(platformName, from, to string, depth int) ([]gap, error) {
+	startDate, err := time.ParseInLocation("2006-01-02", from, location)
+	if err != nil {
+		return nil, err
+	}
+	endDate, err := time.ParseInLocation("2006-01-02", to, location)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := model.CountByDate(dbs, platformName, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return gapsIn(counts, startDate, endDate, depth), nil
+}
+
+// gapsIn returns the dates between start and end (inclusive) with fewer than depth products, given
+// the number of products stored per date.
+func gapsIn(counts map[string]int64, start, end time.Time, depth int) []gap {
+	var gaps []gap
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dateStr := d.Format("2006-01-02")
+		if count := counts[dateStr]; count < int64(depth) {
+			gaps = append(gaps, gap{Date: dateStr, Count: count})
+		}
+	}
+	return gaps
+}
+
+// runGaps implements the "gaps" subcommand. It reports missing and short dates
+// for a platform and, with -repair, re-fetches just those dates.
+func runGaps(args []string) {
+	fs := flag.NewFlagSet("gaps", flag.ExitOnError)
+	platformParam := fs.String("platform", "producthunt", "Platform to scan (default: producthunt)")
+	from := fs.String("from", "", "First date to scan in format YYYY-MM-DD (default: earliest stored date)")
+	to := fs.String("to", "", "Last date to scan in format YYYY-MM-DD (default: yesterday)")
+	repair := fs.Bool("repair", false, "If set, re-fetch the missing and short dates")
+	settleDays := fs.Int("settle-days", 2, "Number of days rankings keep moving before they are final (default 2)")
+	lockWait := fs.Duration("lock-wait", 0, "How long to wait when another receiver is writing the same dates before skipping the repair (default 0)")
+	resolveURLs := fs.Bool("resolve-urls", true, "Follow the redirects of new product links before canonicalizing them (default true)")
+	fs.Parse(args)
+
+	connect()
+	platformClient, depth := newPlatformClient(*platformParam)
+
+	if *from == "" {
+		var earliest model.Product
+		err := dbs.Scopes(model.Ranked).Where("platform = ?", platformClient.GetName()).
+			Order("date ASC").Limit(1).Find(&earliest).Error
+		if err != nil {
+			logging.Fatal("Error finding earliest date", "platform", platformClient.GetName(), "error", err)
+		}
+		if earliest.ID == 0 {
+			logging.Fatal("No stored rankings, use -from to choose where to start", "platform", platformClient.GetName())
+		}
+		*from = earliest.Date.Format("2006-01-02")
+	}
+	if *to == "" {
+		*to = time.Now().In(location).AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	gaps, err := findGaps(platformClient.GetName(), *from, *to, depth)
+	if err != nil {
+		logging.Fatal("Error scanning for gaps", "platform", platformClient.GetName(), "from", *from, "to", *to, "error", err)
+	}
+
+	missing, short := 0, 0
+	fmt.Printf("Gaps for %s from %s to %s (expected top %d):\n", platformClient.GetName(), *from, *to, depth)
+	for _, g := range gaps {
+		if g.Count == 0 {
+			missing++
+			fmt.Printf("%s  missing\n", g.Date)
+		} else {
+			short++
+			fmt.Printf("%s  short (%d of %d)\n", g.Date, g.Count, depth)
+		}
+	}
+	fmt.Printf("\n%d missing, %d short\n", missing, short)
+
+	if !*repair || len(gaps) == 0 {
+		return
+	}
+
+	opts := taskOptions{
+		Depth:       depth,
+		SettleDays:  *settleDays,
+		LockWait:    *lockWait,
+		ResolveURLs: *resolveURLs,
+	}
+	dates := make([]string, len(gaps))
+	for i, g := range gaps {
+		dates[i] = g.Date
+	}
+
+	// Small delay between dates to avoid rate limiting
+	logger, _ := newRunLogger("repair", platformClient.GetName())
+	failed, err := lockedRun(logger, platformClient, dates, opts, 5*time.Second)
+	finishRun(logger, failed, err)
+	logger.Info("Finished repairing dates", "dates", len(dates))
+}
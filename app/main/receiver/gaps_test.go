@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/migrations"
+	"github.com/dariubs/huntline/app/model"
+)
+
+// useTestDB points dbs at a new SQLite database migrated to the latest version.
+func useTestDB(t *testing.T) {
+	t.Helper()
+	gdb, err := db.Open(db.Config{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "huntline.db")})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrations.New(sqlDB, migrations.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Up(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	saved := dbs
+	dbs = gdb
+	t.Cleanup(func() {
+		dbs = saved
+		sqlDB.Close()
+	})
+}
+
+// storeRanking stores count ranked products of a platform's date.
+func storeRanking(t *testing.T, platformName, date string, count int) {
+	t.Helper()
+	d, err := time.ParseInLocation("2006-01-02", date, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	products := make([]model.Product, count)
+	for i := range products {
+		products[i] = model.Product{Name: fmt.Sprintf("product %d", i+1), Rank: uint(i + 1), Date: d, Platform: platformName}
+	}
+	err = model.ReplaceDay(dbs, &model.Day{Platform: platformName, Date: d, FetchedAt: time.Now()}, products)
+	if err != nil {
+		t.Fatalf("storing %s %s: %v", platformName, date, err)
+	}
+}
+
+func TestGapsIn(t *testing.T) {
+	counts := map[string]int64{
+		"2025-01-10": 3,
+		"2025-01-11": 2,
+		"2025-01-13": 2,
+		"2025-01-14": 4,
+	}
+	day := func(date string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", date, location)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"whole range", "2025-01-10", "2025-01-14", "[{2025-01-11 2} {2025-01-12 0} {2025-01-13 2}]"},
+		{"starts before the first stored date", "2025-01-08", "2025-01-10", "[{2025-01-08 0} {2025-01-09 0}]"},
+		{"ends on a gap", "2025-01-14", "2025-01-15", "[{2025-01-15 0}]"},
+		{"single day", "2025-01-12", "2025-01-12", "[{2025-01-12 0}]"},
+		{"single complete day", "2025-01-14", "2025-01-14", "[]"},
+		{"to before from", "2025-01-14", "2025-01-10", "[]"},
+		// Clocks spring forward on 2025-03-09 in the receiver's timezone
+		{"across DST", "2025-03-08", "2025-03-10", "[{2025-03-08 0} {2025-03-09 0} {2025-03-10 0}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gaps := gapsIn(counts, day(tt.from), day(tt.to), 3)
+			if got := fmt.Sprint(gaps); got != tt.want {
+				t.Errorf("gapsIn(%s, %s) = %s, want %s", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindGaps(t *testing.T) {
+	useTestDB(t)
+	storeRanking(t, "producthunt", "2025-01-10", 3)
+	storeRanking(t, "producthunt", "2025-01-11", 2)
+	// 2025-01-12 is missing, only another platform has rankings
+	storeRanking(t, "betalist", "2025-01-12", 3)
+	// 2025-01-13 was full before, the last fetch dropped a product
+	storeRanking(t, "producthunt", "2025-01-13", 3)
+	storeRanking(t, "producthunt", "2025-01-13", 2)
+	storeRanking(t, "producthunt", "2025-01-14", 4)
+
+	gaps, err := findGaps("producthunt", "2025-01-09", "2025-01-15", 3)
+	if err != nil {
+		t.Fatalf("findGaps: %v", err)
+	}
+	want := "[{2025-01-09 0} {2025-01-11 2} {2025-01-12 0} {2025-01-13 2} {2025-01-15 0}]"
+	if got := fmt.Sprint(gaps); got != want {
+		t.Errorf("findGaps = %s, want %s", got, want)
+	}
+
+	if _, err := findGaps("producthunt", "2025-13-01", "2025-01-14", 3); err == nil {
+		t.Error("findGaps with an invalid date succeeded")
+	}
+}
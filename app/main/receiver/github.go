@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"time"
+
+	"github.com/dariubs/huntline/app/github"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// gitHubOptions configures a run of the GitHub enrichment.
+type gitHubOptions struct {
+	// MaxAge is how long the facts of a repository stay fresh
+	MaxAge time.Duration
+	// Limit is the maximum number of repositories fetched per run (0 fetches all that are due)
+	Limit  int
+	Client github.Client
+}
+
+// defaultGitHubOptions are the options of the scheduled github job.
+var defaultGitHubOptions = gitHubOptions{MaxAge: 24 * time.Hour}
+
+// fetchRepositories fetches the GitHub repositories of open-source products last fetched more
+// than MaxAge ago and stores them. It stops early when the API rate limit is reached, leaving the
+// rest for the next run. It returns the number of repositories fetched and of failed fetches.
+func fetchRepositories(ctx context.Context, logger *slog.Logger, opts gitHubOptions) (fetched, failed int, err error) {
+	ctx, span := tracing.Start(ctx, "receiver.fetchRepositories")
+	keys, err := model.RepositoriesToFetch(dbs.WithContext(ctx), time.Now().Add(-opts.MaxAge), opts.Limit)
+	if err != nil {
+		tracing.End(span, err)
+		return 0, 0, err
+	}
+	span.SetAttributes(attribute.Int("repositories", len(keys)))
+	logger.Info("Fetching GitHub repositories", "count", len(keys))
+
+	for _, key := range keys {
+		owner, name, ok := github.ParseRepoURL("https://" + key)
+		if !ok {
+			continue
+		}
+		repo := model.Repository{Key: key, FetchedAt: time.Now()}
+		r, err := opts.Client.Repo(ctx, owner, name)
+		var rateLimit *github.RateLimitError
+		var status *github.StatusError
+		switch {
+		case errors.As(err, &rateLimit):
+			logger.Warn("GitHub rate limit reached, stopping", "reset", rateLimit.Reset)
+			tracing.End(span, nil)
+			return fetched, failed, nil
+		case errors.Is(err, github.ErrNotFound):
+			repo.StatusCode = 404
+		case errors.As(err, &status):
+			repo.StatusCode = status.StatusCode
+		case err == nil:
+			repo.StatusCode = 200
+			repo.FullName = r.FullName
+			repo.Stars = r.Stars
+			repo.Forks = r.Forks
+			repo.License = r.License
+			repo.Language = r.Language
+			repo.Archived = r.Archived
+			if !r.LastCommitAt.IsZero() {
+				repo.LastCommitAt = &r.LastCommitAt
+			}
+		}
+		fetched++
+		if err != nil {
+			failed++
+			repo.Error = err.Error()
+			logger.Debug("Error fetching GitHub repository", "repository", key, "error", err)
+		}
+		err = model.SaveRepository(dbs.WithContext(ctx), &repo)
+		if err != nil {
+			logger.Warn("Error saving GitHub repository", "repository", key, "error", err)
+		}
+	}
+
+	tracing.End(span, nil)
+	return fetched, failed, nil
+}
+
+// runGitHubJob runs the GitHub enrichment and logs the outcome.
+func runGitHubJob(logger *slog.Logger, opts gitHubOptions) {
+	fetched, failed, err := fetchRepositories(context.Background(), logger, opts)
+	if err != nil {
+		logger.Error("GitHub enrichment failed", "error", err)
+		return
+	}
+	logger.Info("GitHub enrichment finished", "fetched", fetched, "failed", failed)
+}
+
+// runGitHub implements the "github" subcommand. It fetches the GitHub repositories that are due once.
+func runGitHub(args []string) {
+	fs := flag.NewFlagSet("github", flag.ExitOnError)
+	maxAge := fs.Duration("max-age", defaultGitHubOptions.MaxAge, "Fetch repositories whose facts are older than this (default 24h)")
+	limit := fs.Int("limit", 0, "Maximum number of repositories to fetch (default 0, all that are due)")
+	fs.Parse(args)
+
+	connect()
+	logger := slog.With("job", jobGitHub, "run", logging.NewID())
+	runGitHubJob(logger, gitHubOptions{
+		MaxAge: *maxAge,
+		Limit:  *limit,
+		Client: github.NewClientFromEnv(),
+	})
+	err := flushTraces(context.Background())
+	if err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/model"
+)
+
+// runIdentities implements the "identities" subcommand. It links the stored products to their
+// product across platforms, and manages the overrides of domain keys that belong together.
+func runIdentities(args []string) {
+	fs := flag.NewFlagSet("identities", flag.ExitOnError)
+	link := fs.String("link", "", "Link the launches of a domain key to another product, as domain=target, e.g. app.example.com=example.com")
+	unlink := fs.String("unlink", "", "Remove the override of a domain key, moving its launches back to their own product")
+	list := fs.Bool("list", false, "List the overrides")
+	fs.Parse(args)
+
+	connect()
+
+	switch {
+	case *list:
+		var overrides []model.IdentityOverride
+		err := dbs.Order("key ASC").Find(&overrides).Error
+		if err != nil {
+			logging.Fatal("Error loading overrides", "error", err)
+		}
+		for _, o := range overrides {
+			fmt.Printf("%s -> %s\n", o.Key, o.Target)
+		}
+		fmt.Printf("\n%d overrides\n", len(overrides))
+
+	case *link != "":
+		domain, target, ok := strings.Cut(*link, "=")
+		if !ok {
+			logging.Fatal("Invalid -link, expected domain=target", "link", *link)
+		}
+		err := model.SetIdentityOverride(dbs, strings.TrimSpace(domain), strings.TrimSpace(target))
+		if err != nil {
+			logging.Fatal("Error linking domain", "domain", domain, "target", target, "error", err)
+		}
+		fmt.Printf("Linked %s to %s\n", domain, target)
+
+	case *unlink != "":
+		err := model.DeleteIdentityOverride(dbs, *unlink)
+		if err != nil {
+			logging.Fatal("Error unlinking domain", "domain", *unlink, "error", err)
+		}
+		fmt.Printf("Unlinked %s\n", *unlink)
+
+	default:
+		linked, err := model.LinkIdentities(dbs)
+		if err != nil {
+			logging.Fatal("Error linking products", "linked", linked, "error", err)
+		}
+		fmt.Printf("Linked %d products\n", linked)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/github"
+	"github.com/dariubs/huntline/app/platform"
+	"github.com/dariubs/huntline/app/schedule"
+)
+
+// Kinds of scheduled job. Each kind decides which dates a run fetches.
+const (
+	// jobRun fetches today and re-fetches the settle window (the default job)
+	jobRun = "run"
+	// jobToday fetches only today, for intraday polling
+	jobToday = "today"
+	// jobSettle re-fetches only the previous days of the settle window
+	jobSettle = "settle"
+	// jobGaps repairs missing and short dates of the last gap-days days
+	jobGaps = "gaps"
+	// jobLinks checks the product websites instead of fetching dates
+	jobLinks = "links"
+	// jobEnrich refreshes the metadata of the product websites instead of fetching dates
+	jobEnrich = "enrich"
+	// jobGitHub refreshes the GitHub repositories of open-source products instead of fetching dates
+	jobGitHub = "github"
+)
+
+var jobKinds = []string{jobRun, jobToday, jobSettle, jobGaps, jobLinks, jobEnrich, jobGitHub}
+
+// jobFlags collects repeated -job flags of the form kind=cron-expression.
+type jobFlags []string
+
+func (j *jobFlags) String() string {
+	return strings.Join(*j, "; ")
+}
+
+func (j *jobFlags) Set(value string) error {
+	*j = append(*j, value)
+	return nil
+}
+
+// scheduleToCron accepts a cron expression or a legacy daily HH:MM time and returns a cron expression.
+func scheduleToCron(value string) string {
+	if t, err := time.Parse("15:04", value); err == nil {
+		return fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour())
+	}
+	return value
+}
+
+// jobDates returns the dates a scheduled job of the given kind fetches now.
+func jobDates(kind string, platformClient platform.LaunchPlatform, opts taskOptions, gapDays int) ([]string, error) {
+	today := getToday()
+	switch kind {
+	case jobRun:
+		return settleDates(today, opts.SettleDays), nil
+	case jobToday:
+		return []string{today}, nil
+	case jobSettle:
+		dates := settleDates(today, opts.SettleDays)
+		return dates[:len(dates)-1], nil
+	case jobGaps:
+		now := time.Now().In(location)
+		from := now.AddDate(0, 0, -gapDays).Format("2006-01-02")
+		to := now.AddDate(0, 0, -1).Format("2006-01-02")
+		gaps, err := findGaps(platformClient.GetName(), from, to, opts.Depth)
+		if err != nil {
+			return nil, err
+		}
+		dates := make([]string, len(gaps))
+		for i, g := range gaps {
+			dates[i] = g.Date
+		}
+		return dates, nil
+	}
+	return nil, fmt.Errorf("unknown job kind %q, expected one of: %s", kind, strings.Join(jobKinds, ", "))
+}
+
+// newJob builds a scheduled job from a kind=cron-expression definition.
+func newJob(definition string, loc *time.Location, platformClient platform.LaunchPlatform, opts taskOptions, gapDays int) (schedule.Job, error) {
+	kind, expr, ok := strings.Cut(definition, "=")
+	if !ok {
+		return schedule.Job{}, fmt.Errorf("invalid job %q: expected kind=cron-expression", definition)
+	}
+	kind = strings.TrimSpace(kind)
+	if !isKnownJob(kind) {
+		return schedule.Job{}, fmt.Errorf("unknown job kind %q, expected one of: %s", kind, strings.Join(jobKinds, ", "))
+	}
+
+	cron, err := schedule.Parse(expr, loc)
+	if err != nil {
+		return schedule.Job{}, err
+	}
+
+	switch kind {
+	case jobLinks:
+		run := func() {
+			runLinkJob(slog.With("job", kind), defaultLinkOptions)
+		}
+		return schedule.Job{Name: kind, Cron: cron, Run: run}, nil
+	case jobEnrich:
+		run := func() {
+			runEnrichJob(slog.With("job", kind), defaultEnrichOptions)
+		}
+		return schedule.Job{Name: kind, Cron: cron, Run: run}, nil
+	case jobGitHub:
+		opts := defaultGitHubOptions
+		opts.Client = github.NewClientFromEnv()
+		run := func() {
+			runGitHubJob(slog.With("job", kind), opts)
+		}
+		return schedule.Job{Name: kind, Cron: cron, Run: run}, nil
+	}
+
+	run := func() {
+		logger := slog.With("platform", platformClient.GetName(), "job", kind)
+		dates, err := jobDates(kind, platformClient, opts, gapDays)
+		if err != nil {
+			logger.Error("Job failed", "error", err)
+			return
+		}
+		if len(dates) == 0 {
+			logger.Info("Job has no dates to fetch")
+			return
+		}
+
+		// Pause between dates to avoid rate limiting
+		trackedRun(kind, platformClient, dates, opts, 5*time.Second)
+	}
+
+	return schedule.Job{Name: kind, Cron: cron, Run: run}, nil
+}
+
+// logRunResult logs the outcome of a scheduled or manual run. A run skipped because another
+// receiver holds the dates has already been logged with the lock holders.
+func logRunResult(logger *slog.Logger, failed []string, err error) {
+	switch {
+	case errors.Is(err, errLocked):
+	case err != nil:
+		logger.Error("Run failed", "error", err)
+	case len(failed) > 0:
+		logger.Error("Run failed for some dates", "failed", strings.Join(failed, ", "))
+	default:
+		logger.Info("Run finished")
+	}
+}
+
+// isKnownJob reports whether kind is a supported job kind.
+func isKnownJob(kind string) bool {
+	for _, k := range jobKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
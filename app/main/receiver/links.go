@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"time"
+
+	"github.com/dariubs/huntline/app/linkcheck"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/metrics"
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// linkOptions configures a run of the link checker.
+type linkOptions struct {
+	// MaxAge is how long a website's last check stays fresh
+	MaxAge time.Duration
+	// Limit is the maximum number of websites checked per run (0 checks all that are due)
+	Limit   int
+	Checker linkcheck.Checker
+}
+
+// defaultLinkOptions are the options of the scheduled links job.
+var defaultLinkOptions = linkOptions{MaxAge: 7 * 24 * time.Hour}
+
+// checkLinks checks the product websites whose last check is older than MaxAge and records
+// each result. It returns the number of websites checked per status.
+func checkLinks(ctx context.Context, logger *slog.Logger, opts linkOptions) (map[string]int, error) {
+	ctx, span := tracing.Start(ctx, "receiver.checkLinks")
+	links, err := model.LinksToCheck(dbs.WithContext(ctx), time.Now().Add(-opts.MaxAge), opts.Limit)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("links", len(links)))
+	logger.Info("Checking product websites", "count", len(links))
+
+	counts := make(map[string]int)
+	results := make(chan model.LinkCheck)
+	done := make(chan struct{})
+	go func() {
+		// Record from a single goroutine so the counts need no locking
+		for check := range results {
+			counts[check.Status]++
+			metrics.LinkChecks.WithLabelValues(check.Status).Inc()
+			logger.Debug("Checked product website", "url", check.URL, "status", check.Status,
+				"code", check.StatusCode, "target", check.Target, "error", check.Error, "latency_ms", check.LatencyMS)
+			err := model.RecordLinkCheck(dbs.WithContext(ctx), &check)
+			if err != nil {
+				logger.Warn("Error recording website check", "url", check.URL, "error", err)
+			}
+		}
+		close(done)
+	}()
+	opts.Checker.CheckAll(ctx, links, func(check model.LinkCheck) {
+		results <- check
+	})
+	close(results)
+	<-done
+
+	tracing.End(span, nil)
+	return counts, nil
+}
+
+// runLinkJob runs the link checker and logs the outcome.
+func runLinkJob(logger *slog.Logger, opts linkOptions) {
+	counts, err := checkLinks(context.Background(), logger, opts)
+	if err != nil {
+		logger.Error("Link check failed", "error", err)
+		return
+	}
+	logger.Info("Link check finished", "up", counts[model.LinkUp], "moved", counts[model.LinkMoved],
+		"down", counts[model.LinkDown], "unknown", counts[model.LinkUnknown])
+}
+
+// runLinks implements the "links" subcommand. It checks the product websites that are due once.
+func runLinks(args []string) {
+	fs := flag.NewFlagSet("links", flag.ExitOnError)
+	maxAge := fs.Duration("max-age", defaultLinkOptions.MaxAge, "Check websites whose last check is older than this (default 168h)")
+	limit := fs.Int("limit", 0, "Maximum number of websites to check (default 0, all that are due)")
+	concurrency := fs.Int("concurrency", linkcheck.DefaultConcurrency, "Number of hosts checked at the same time (default 8)")
+	hostDelay := fs.Duration("host-delay", linkcheck.DefaultHostDelay, "Pause between two requests to the same host (default 2s)")
+	fs.Parse(args)
+
+	connect()
+	logger := slog.With("job", jobLinks, "run", logging.NewID())
+	runLinkJob(logger, linkOptions{
+		MaxAge:  *maxAge,
+		Limit:   *limit,
+		Checker: linkcheck.Checker{Concurrency: *concurrency, HostDelay: *hostDelay},
+	})
+	err := flushTraces(context.Background())
+	if err != nil {
+		logger.Warn("Failed to flush traces", "error", err)
+	}
+}
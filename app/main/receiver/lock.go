@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/logging"
+)
+
+// lockNamespace prefixes the platform name in the first advisory lock key.
+// The second key is the month (year*12 + month) of the dates being written.
+const lockNamespace = "huntline:receiver:"
+
+// lockRetryInterval is how often a waiting receiver retries the lock.
+const lockRetryInterval = 5 * time.Second
+
+// errLocked is returned when another receiver holds part of the requested date range.
+var errLocked = errors.New("dates are locked by another receiver")
+
+// lockHolder describes a database session holding a receiver lock.
+type lockHolder struct {
+	PID          int
+	Application  string
+	ClientAddr   string
+	BackendStart time.Time
+	Platform     string
+	Month        string
+}
+
+func (h lockHolder) String() string {
+	return fmt.Sprintf("%s %s held by %q (pid %d, client %s, connected %s)",
+		h.Platform, h.Month, h.Application, h.PID, h.ClientAddr, h.BackendStart.Format(time.RFC3339))
+}
+
+// rangeLock holds Postgres advisory locks on every month of a platform's date range
+// on a dedicated connection, so receivers writing overlapping dates never run at once.
+// SQLite has no advisory locks; there the lock has no connection and holds nothing, which is
+// safe enough for a single file: its writers wait for each other and every day is replaced in
+// one transaction.
+type rangeLock struct {
+	conn     *sql.Conn
+	platform string
+	months   []int
+}
+
+// lockMonths returns the sorted months (year*12 + month) covered by the given dates.
+func lockMonths(dates []string) []int {
+	seen := make(map[int]bool)
+	var months []int
+	for _, date := range dates {
+		d, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		month := d.Year()*12 + int(d.Month()) - 1
+		if !seen[month] {
+			seen[month] = true
+			months = append(months, month)
+		}
+	}
+	sort.Ints(months)
+	return months
+}
+
+// monthString formats a month key as YYYY-MM.
+func monthString(month int) string {
+	return fmt.Sprintf("%04d-%02d", month/12, month%12+1)
+}
+
+// acquireRangeLock locks the months covering dates for the platform, retrying until wait has elapsed.
+// When the range stays locked it returns errLocked along with the sessions holding it.
+func acquireRangeLock(ctx context.Context, platformName string, dates []string, wait time.Duration) (*rangeLock, []lockHolder, error) {
+	if dbs.Dialector.Name() == db.DriverSQLite {
+		return &rangeLock{platform: platformName}, nil, nil
+	}
+
+	sqlDB, err := dbs.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Name the session so other receivers can tell who holds the lock
+	hostname, _ := os.Hostname()
+	_, err = conn.ExecContext(ctx, "SELECT set_config('application_name', $1, false)",
+		fmt.Sprintf("huntline-receiver %s:%d", hostname, os.Getpid()))
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	lock := &rangeLock{conn: conn, platform: platformName}
+	months := lockMonths(dates)
+	deadline := time.Now().Add(wait)
+	for {
+		acquired, err := lock.tryLock(ctx, months)
+		if err != nil {
+			lock.Release()
+			return nil, nil, err
+		}
+		if acquired {
+			return lock, nil, nil
+		}
+		if !time.Now().Before(deadline) {
+			holders, err := lockHolders(ctx, conn, platformName)
+			lock.Release()
+			if err != nil {
+				return nil, nil, err
+			}
+			return nil, holders, errLocked
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// tryLock tries to lock every month in order. If any month is taken, the months
+// locked so far are released again and tryLock reports false.
+func (l *rangeLock) tryLock(ctx context.Context, months []int) (bool, error) {
+	for _, month := range months {
+		var acquired bool
+		err := l.conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1), $2)",
+			lockNamespace+l.platform, month).Scan(&acquired)
+		if err != nil {
+			return false, err
+		}
+		if !acquired {
+			l.unlock(ctx)
+			return false, nil
+		}
+		l.months = append(l.months, month)
+	}
+	return true, nil
+}
+
+// unlock releases the months locked so far.
+func (l *rangeLock) unlock(ctx context.Context) {
+	for _, month := range l.months {
+		_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1), $2)", lockNamespace+l.platform, month)
+		if err != nil {
+			slog.Error("Error releasing receiver lock", "platform", l.platform, "month", monthString(month), "error", err)
+		}
+	}
+	l.months = nil
+}
+
+// Release unlocks the range and returns the connection to the pool, without the session name
+// that marked it as a lock holder.
+func (l *rangeLock) Release() {
+	if l.conn == nil {
+		return
+	}
+	l.unlock(context.Background())
+	_, err := l.conn.ExecContext(context.Background(), "RESET application_name")
+	if err != nil {
+		slog.Error("Error resetting receiver lock session name", "platform", l.platform, "error", err)
+	}
+	l.conn.Close()
+}
+
+// Describe returns the locked range for the run output.
+func (l *rangeLock) Describe() string {
+	if l.conn == nil {
+		return l.platform + " (not locked on SQLite)"
+	}
+	if len(l.months) == 0 {
+		return l.platform + " (no dates)"
+	}
+	return fmt.Sprintf("%s %s to %s", l.platform, monthString(l.months[0]), monthString(l.months[len(l.months)-1]))
+}
+
+// lockHolders lists the sessions holding receiver locks for the platform, or for every platform when it is empty.
+func lockHolders(ctx context.Context, conn *sql.Conn, platformName string) ([]lockHolder, error) {
+	query := `SELECT a.pid, COALESCE(a.application_name, ''), COALESCE(host(a.client_addr), 'local'), a.backend_start, l.classid::bigint, l.objid::bigint
+		FROM pg_locks l JOIN pg_stat_activity a ON a.pid = l.pid
+		WHERE l.locktype = 'advisory' AND l.granted AND l.objsubid = 2
+		ORDER BY l.classid, l.objid`
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Map lock keys back to platform names
+	platforms, err := lockPlatforms(ctx, conn, platformName)
+	if err != nil {
+		return nil, err
+	}
+
+	var holders []lockHolder
+	for rows.Next() {
+		var h lockHolder
+		var classID, objID int64
+		err = rows.Scan(&h.PID, &h.Application, &h.ClientAddr, &h.BackendStart, &classID, &objID)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := platforms[classID]
+		if !ok {
+			continue
+		}
+		h.Platform = name
+		h.Month = monthString(int(objID))
+		holders = append(holders, h)
+	}
+	return holders, rows.Err()
+}
+
+// lockPlatforms returns the first lock key of each platform as it appears in pg_locks.
+func lockPlatforms(ctx context.Context, conn *sql.Conn, platformName string) (map[int64]string, error) {
+	names := []string{platformName}
+	if platformName == "" {
+		names = supportedPlatforms
+	}
+
+	platforms := make(map[int64]string, len(names))
+	for _, name := range names {
+		var key int64
+		err := conn.QueryRowContext(ctx, "SELECT hashtext($1)::oid::bigint", lockNamespace+name).Scan(&key)
+		if err != nil {
+			return nil, err
+		}
+		platforms[key] = name
+	}
+	return platforms, nil
+}
+
+// runWithLock runs fn while holding the receiver lock on the dates of a platform.
+// If the dates stay locked after waiting, it logs the lock holders and returns errLocked.
+func runWithLock(logger *slog.Logger, platformName string, dates []string, wait time.Duration, fn func()) error {
+	lock, holders, err := acquireRangeLock(context.Background(), platformName, dates, wait)
+	if errors.Is(err, errLocked) {
+		logger.Warn("Skipping run", "error", err)
+		for _, h := range holders {
+			logger.Warn("Lock holder", "holder", h.String())
+		}
+		return err
+	}
+	if err != nil {
+		return fmt.Errorf("acquiring receiver lock: %w", err)
+	}
+	defer lock.Release()
+
+	logger.Info("Acquired receiver lock", "lock", lock.Describe())
+	fn()
+	return nil
+}
+
+// runLocks implements the "locks" subcommand, listing the receivers currently holding locks.
+func runLocks(args []string) {
+	fs := flag.NewFlagSet("locks", flag.ExitOnError)
+	platformParam := fs.String("platform", "", "Only list locks of this platform (default: all platforms)")
+	fs.Parse(args)
+
+	connect()
+	if dbs.Dialector.Name() == db.DriverSQLite {
+		fmt.Println("No receiver locks on SQLite")
+		return
+	}
+	ctx := context.Background()
+	sqlDB, err := dbs.DB()
+	if err != nil {
+		logging.Fatal("Failed to access database", "error", err)
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		logging.Fatal("Failed to open database connection", "error", err)
+	}
+	defer conn.Close()
+
+	holders, err := lockHolders(ctx, conn, *platformParam)
+	if err != nil {
+		logging.Fatal("Error listing receiver locks", "error", err)
+	}
+	if len(holders) == 0 {
+		fmt.Println("No receiver locks held")
+		return
+	}
+	fmt.Println("Receiver locks:")
+	for _, h := range holders {
+		fmt.Println(h)
+	}
+}
@@ -1,64 +1,137 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/envfile"
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/metrics"
 	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/notify"
 	"github.com/dariubs/huntline/app/platform"
 	"github.com/dariubs/huntline/app/platform/producthunt"
-	"github.com/joho/godotenv"
+	"github.com/dariubs/huntline/app/schedule"
+	"github.com/dariubs/huntline/app/tracing"
+	"github.com/dariubs/huntline/app/types"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/gorm"
 )
 
 var dbs *gorm.DB
 
-// getToday returns today's date as a formatted string in San Francisco timezone (Pacific Time).
+// notifier posts the daily top lists to chat, or is nil when NOTIFY_CONFIG is unset.
+var notifier *notify.Notifier
+
+// flushTraces sends the spans still buffered by the tracer. One-off runs call it before exiting.
+var flushTraces = func(context.Context) error { return nil }
+
+// supportedPlatforms lists the platforms newPlatformClient can create.
+var supportedPlatforms = []string{"producthunt"}
+
+// location is the timezone of the receiver's dates and schedules, set from the -timezone flag.
+var location = types.SanFranciscoLocation()
+
+// getToday returns today's date as a formatted string in the receiver's timezone.
 func getToday() string {
-	loc, err := time.LoadLocation("America/Los_Angeles")
+	today := time.Now().In(location)
+	return today.Format("2006-01-02")
+}
+
+// taskOptions configures how runTaskForDate fetches and stores a day's rankings.
+type taskOptions struct {
+	// Depth is the number of top products to fetch
+	Depth int
+	// SettleDays is the number of days rankings keep moving before they are final
+	SettleDays int
+	// DryRun prints the changes a run would make instead of writing them
+	DryRun bool
+	// DiffFormat is the dry-run output format: "text" or "json"
+	DiffFormat string
+	// LockWait is how long to wait for another receiver writing the same dates
+	LockWait time.Duration
+	// ResolveURLs follows the redirects of product links before canonicalizing them
+	ResolveURLs bool
+}
+
+// settleDates returns the dates a scheduled run fetches: the previous settleDays days, oldest first, followed by today.
+func settleDates(today string, settleDays int) []string {
+	day, err := time.ParseInLocation("2006-01-02", today, location)
 	if err != nil {
-		log.Fatalf("Failed to load timezone: %v", err)
+		logging.Fatal("Invalid date", "date", today, "error", err)
 	}
-	today := time.Now().In(loc)
-	return today.Format("2006-01-02")
+
+	dates := make([]string, 0, settleDays+1)
+	for offset := settleDays; offset > 0; offset-- {
+		dates = append(dates, day.AddDate(0, 0, -offset).Format("2006-01-02"))
+	}
+	return append(dates, today)
 }
 
-// runAtScheduledTime schedules a given task to run at a specified hour and minute (San Francisco timezone - Pacific Time).
-func runAtScheduledTime(task func(), hour, minute int) {
-	loc, err := time.LoadLocation("America/Los_Angeles")
+// isFinal reports whether the settle window of the given date has elapsed.
+func isFinal(date string, settleDays int) bool {
+	day, err := time.ParseInLocation("2006-01-02", date, location)
 	if err != nil {
-		log.Fatalf("Failed to load timezone: %v", err)
+		return false
 	}
+	today, _ := time.ParseInLocation("2006-01-02", getToday(), location)
+	return !day.After(today.AddDate(0, 0, -settleDays))
+}
 
-	for {
-		now := time.Now().In(loc)
-		nextRun := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, loc)
-		if now.After(nextRun) {
-			nextRun = nextRun.Add(24 * time.Hour)
-		}
-		log.Printf("Next run scheduled at: %s (San Francisco/Pacific Time)", nextRun)
-		time.Sleep(time.Until(nextRun))
-		task()
+// isRecent reports whether the date is inside its settle window or just became final, the dates
+// scheduled runs store. Older dates are only stored by backfills and repairs.
+func isRecent(date string, settleDays int) bool {
+	day, err := time.ParseInLocation("2006-01-02", date, location)
+	if err != nil {
+		return false
 	}
+	today, _ := time.ParseInLocation("2006-01-02", getToday(), location)
+	return !day.Before(today.AddDate(0, 0, -settleDays))
 }
 
 // runTaskForDate executes the product fetching and persistence task for a given date and platform.
-// It fetches top 10 products, updates existing ones, and removes products that are no longer in top 10.
-func runTaskForDate(platformClient platform.LaunchPlatform, date string) {
-	products, err := platformClient.GetTopProducts(date, 10)
+// It fetches the top products and replaces the stored rankings of the date in a single transaction,
+// keeping products that are no longer in the top list as dropped and recording the day as final once
+// its settle window has elapsed. It returns an error if the date was left unchanged.
+func runTaskForDate(ctx context.Context, logger *slog.Logger, platformClient platform.LaunchPlatform, date string, opts taskOptions) error {
+	tx := dbs.WithContext(ctx)
+
+	fetchCtx, fetchSpan := tracing.Start(ctx, "platform.GetTopProducts",
+		attribute.String("platform", platformClient.GetName()),
+		attribute.Int("depth", opts.Depth))
+	fetchStart := time.Now()
+	products, err := platformClient.GetTopProducts(fetchCtx, date, opts.Depth)
+	metrics.FetchDuration.WithLabelValues(platformClient.GetName()).Observe(time.Since(fetchStart).Seconds())
+	fetchSpan.SetAttributes(attribute.Int("products", len(products)))
+	tracing.End(fetchSpan, err)
 	if err != nil {
-		log.Fatalf("Error fetching products for platform %s on date %s: %v", platformClient.GetName(), date, err)
+		metrics.FetchErrors.WithLabelValues(platformClient.GetName()).Inc()
+		return fmt.Errorf("fetching products for platform %s on date %s: %w", platformClient.GetName(), date, err)
 	}
 
 	// Get all existing products for this date and platform
 	var existingProducts []model.Product
-	dbs.Where("date = ? AND platform = ?", date, platformClient.GetName()).Find(&existingProducts)
+	err = tx.Where("date = ? AND platform = ?", date, platformClient.GetName()).Order("rank ASC").Find(&existingProducts).Error
+	if err != nil {
+		return fmt.Errorf("loading stored products for platform %s on date %s: %w", platformClient.GetName(), date, err)
+	}
+
+	// In dry-run mode, report what would change and leave the database untouched
+	if opts.DryRun {
+		printDiff(os.Stdout, dateDiff{
+			Platform: platformClient.GetName(),
+			Date:     date,
+			Changes:  diffProducts(existingProducts, products),
+		}, opts.DiffFormat)
+		return nil
+	}
 
 	// Create a map of fetched product names for quick lookup
 	fetchedProductNames := make(map[string]bool)
@@ -66,133 +139,345 @@ func runTaskForDate(platformClient platform.LaunchPlatform, date string) {
 		fetchedProductNames[product.Name] = true
 	}
 
-	// Save or update fetched products
-	fmt.Printf("Top Products from %s on %s:\n", platformClient.GetName(), date)
-	for _, product := range products {
-		fmt.Printf("Name: %s\nTagline: %s\nWebsite: %s\nRank: %d\nPlatform: %s\n\n",
-			product.Name, product.Tagline, product.URL, product.Rank, product.Platform)
+	logger.Info("Fetched top products", "count", len(products))
+	pdcs := make([]model.Product, len(products))
+	for i, product := range products {
+		logger.Debug("Fetched product", "rank", product.Rank, "name", product.Name,
+			"tagline", product.Tagline, "url", product.URL)
 
-		pdc := model.Product{
+		pdcs[i] = model.Product{
 			Name:        product.Name,
 			Tagline:     product.Tagline,
-			URL:         product.URL,
+			RawURL:      product.URL,
 			Rank:        product.Rank,
 			Logo:        product.Logo,
 			Date:        product.Date,
 			Platform:    product.Platform,
 			Description: product.Description,
 		}
+	}
+
+	// Store the canonical URL of each product's site next to the platform's link
+	canonicalizeURLs(ctx, logger, pdcs, existingProducts, opts.ResolveURLs)
+
+	// Record the fetch, marking the day as final once its settle window has elapsed
+	parsedDate, _ := time.ParseInLocation("2006-01-02", date, location)
+	day := model.Day{
+		Platform:  platformClient.GetName(),
+		Date:      parsedDate,
+		FetchedAt: time.Now(),
+	}
+	if isFinal(date, opts.SettleDays) {
+		day.FinalAt = &day.FetchedAt
+	}
+
+	// Replace the rankings of the date atomically, linking each launch to the product across platforms
+	err = model.ReplaceDay(tx, &day, pdcs)
+	if err != nil {
+		return fmt.Errorf("replacing rankings for platform %s on date %s (rolled back, stored rankings unchanged): %w",
+			platformClient.GetName(), date, err)
+	}
+	metrics.ProductsIngested.WithLabelValues(platformClient.GetName()).Add(float64(len(pdcs)))
+
+	for _, existingProduct := range existingProducts {
+		if existingProduct.DroppedAt == nil && !fetchedProductNames[existingProduct.Name] {
+			logger.Info("Dropped product", "name", existingProduct.Name, "rank", existingProduct.Rank, "depth", opts.Depth)
+		}
+	}
+	logger.Info("Stored rankings", "products", len(pdcs), "final", day.FinalAt != nil)
 
-		err = pdc.Save(dbs)
+	// Post the top list to chat; the rankings are stored even if this fails. Backfilled dates are
+	// not posted: they are all final already, and a backfill would post every one of them.
+	if notifier != nil && isRecent(date, opts.SettleDays) {
+		sent, err := notifier.Notify(ctx, notify.Digest{
+			Platform: platformClient.GetName(),
+			Date:     day.Date,
+			Final:    day.FinalAt != nil,
+			Products: pdcs,
+		})
+		if len(sent) > 0 {
+			logger.Info("Posted top products", "sinks", strings.Join(sent, ", "))
+		}
 		if err != nil {
-			log.Printf("Error saving product %s: %v", product.Name, err)
+			logger.Warn("Error posting top products", "error", err)
 		}
 	}
+	return nil
+}
 
-	// Remove products that are no longer in top 10
-	for _, existingProduct := range existingProducts {
-		if !fetchedProductNames[existingProduct.Name] {
-			result := dbs.Delete(&existingProduct)
-			if result.Error != nil {
-				log.Printf("Error deleting product %s: %v", existingProduct.Name, result.Error)
-			} else {
-				fmt.Printf("Removed product %s (no longer in top 10)\n", existingProduct.Name)
-			}
+// newRunLogger returns the logger of a receiver run, tagged with the platform, the job and a new run ID,
+// and the run ID itself.
+func newRunLogger(job, platformName string) (*slog.Logger, string) {
+	runID := logging.NewID()
+	return slog.With("platform", platformName, "job", job, "run", runID), runID
+}
+
+// runDates runs the task for each date in order, pausing between dates to avoid rate limiting.
+// A failed date is reported and skipped; the failed dates are returned.
+// Each run is a trace with a span per date.
+func runDates(logger *slog.Logger, platformClient platform.LaunchPlatform, dates []string, opts taskOptions, pause time.Duration) []string {
+	ctx, span := tracing.Start(context.Background(), "receiver.run",
+		attribute.String("platform", platformClient.GetName()),
+		attribute.Int("dates", len(dates)),
+		attribute.Bool("dry_run", opts.DryRun))
+	defer span.End()
+
+	var failed []string
+	for i, date := range dates {
+		if i > 0 {
+			time.Sleep(pause)
+		}
+		dateLogger := logger.With("date", date)
+		dateLogger.Info("Processing date")
+		dateCtx, dateSpan := tracing.Start(ctx, "receiver.date", attribute.String("date", date))
+		err := runTaskForDate(dateCtx, dateLogger, platformClient, date, opts)
+		tracing.End(dateSpan, err)
+		if err != nil {
+			dateLogger.Error("Date failed", "error", err)
+			failed = append(failed, date)
+		}
+	}
+	span.SetAttributes(attribute.Int("failed", len(failed)))
+	return failed
+}
+
+// lockedRun runs the task for the dates and returns the failed dates. It holds the receiver lock
+// on one month of dates at a time, so a long backfill does not keep a scheduled run of another
+// month waiting. A month another receiver holds is skipped, and the run then returns errLocked
+// after the other months. Dry runs do not write, so they run without the lock.
+func lockedRun(logger *slog.Logger, platformClient platform.LaunchPlatform, dates []string, opts taskOptions, pause time.Duration) ([]string, error) {
+	if opts.DryRun {
+		return runDates(logger, platformClient, dates, opts, pause), nil
+	}
+
+	var failed []string
+	var lockErr error
+	for i, chunk := range monthChunks(dates) {
+		if i > 0 {
+			time.Sleep(pause)
 		}
+		err := runWithLock(logger, platformClient.GetName(), chunk, opts.LockWait, func() {
+			failed = append(failed, runDates(logger, platformClient, chunk, opts, pause)...)
+		})
+		if errors.Is(err, errLocked) {
+			lockErr = err
+			continue
+		}
+		if err != nil {
+			return failed, err
+		}
+	}
+	return failed, lockErr
+}
+
+// monthChunks splits dates (YYYY-MM-DD, in order) into runs of consecutive dates of the same month.
+func monthChunks(dates []string) [][]string {
+	var chunks [][]string
+	for i, date := range dates {
+		if i == 0 || date[:7] != dates[i-1][:7] {
+			chunks = append(chunks, nil)
+		}
+		chunks[len(chunks)-1] = append(chunks[len(chunks)-1], date)
+	}
+	return chunks
+}
+
+// finishRun ends a one-off run. It exits cleanly when another receiver holds the dates
+// and with a non-zero status if the lock could not be taken or any date failed.
+func finishRun(logger *slog.Logger, failed []string, err error) {
+	flushErr := flushTraces(context.Background())
+	if flushErr != nil {
+		logger.Warn("Failed to flush traces", "error", flushErr)
+	}
+	if errors.Is(err, errLocked) {
+		os.Exit(0)
+	}
+	if err != nil {
+		logger.Error("Run failed", "error", err)
+		os.Exit(1)
+	}
+	if len(failed) > 0 {
+		logger.Error("Run failed for some dates", "failed", strings.Join(failed, ", "))
+		os.Exit(1)
 	}
 }
 
+// dateRange returns every date from start to end inclusive in format YYYY-MM-DD.
+func dateRange(start, end time.Time) []string {
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+	return dates
+}
+
+// connect loads environment variables and initializes the database connection.
+func connect() {
+	err := envfile.Load()
+	if err != nil {
+		logging.Fatal("Error loading .env file", "error", err)
+	}
+
+	err = logging.Setup()
+	if err != nil {
+		logging.Fatal("Invalid logging configuration", "error", err)
+	}
+
+	flushTraces, err = tracing.Setup("huntline-receiver")
+	if err != nil {
+		logging.Fatal("Invalid tracing configuration", "error", err)
+	}
+
+	dbConfig, err := db.ConfigFromEnv()
+	if err != nil {
+		logging.Fatal("Invalid database configuration", "error", err)
+	}
+	dbs, err = db.Open(dbConfig)
+	if err != nil {
+		logging.Fatal("Failed to connect to database", "error", err)
+	}
+
+	err = metrics.InstrumentGORM(dbs)
+	if err != nil {
+		logging.Fatal("Failed to instrument database", "error", err)
+	}
+	err = tracing.InstrumentGORM(dbs)
+	if err != nil {
+		logging.Fatal("Failed to instrument database", "error", err)
+	}
+
+	if path := os.Getenv("NOTIFY_CONFIG"); path != "" {
+		notifier, err = notify.Load(dbs, path)
+		if err != nil {
+			logging.Fatal("Invalid notifier configuration", "error", err)
+		}
+	}
+}
+
+// newPlatformClient initializes the platform client for the given platform name
+// and returns it with the platform's configured top-N depth.
+func newPlatformClient(name string) (platform.LaunchPlatform, int) {
+	switch name {
+	case "producthunt":
+		apiKey := os.Getenv("PH_API_KEY")
+		if apiKey == "" {
+			logging.Fatal("PH_API_KEY environment variable is required for ProductHunt platform")
+		}
+		depth, err := platform.Depth(name)
+		if err != nil {
+			logging.Fatal("Invalid platform configuration", "error", err)
+		}
+		return producthunt.NewProductHuntPlatform(apiKey), depth
+	default:
+		logging.Fatal("Unsupported platform", "platform", name, "supported", strings.Join(supportedPlatforms, ", "))
+	}
+	return nil, 0
+}
+
 func main() {
+	// Subcommands take their own flags.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "gaps":
+			runGaps(os.Args[2:])
+			return
+		case "locks":
+			runLocks(os.Args[2:])
+			return
+		case "links":
+			runLinks(os.Args[2:])
+			return
+		case "identities":
+			runIdentities(os.Args[2:])
+			return
+		case "enrich":
+			runEnrich(os.Args[2:])
+			return
+		case "github":
+			runGitHub(os.Args[2:])
+			return
+		}
+	}
+
 	// Define command-line flags.
 	runNow := flag.Bool("run-now", true, "Run the task immediately before starting the scheduler")
 	dateParam := flag.String("date", "", "Date in format YYYY-MM-DD to fetch data (overrides default 'today')")
 	repeatable := flag.Bool("repeat", false, "Set task to run repeatedly according to the schedule (default true)")
-	schedule := flag.String("schedule", "00:30", "Schedule time in 24hr format (HH:MM) when the task should run (default 00:30)")
+	scheduleParam := flag.String("schedule", "30 0 * * *", "Cron expression (or daily HH:MM time) of the default run job (default \"30 0 * * *\")")
+	timezone := flag.String("timezone", "America/Los_Angeles", "Timezone of the fetched dates and the schedules (default America/Los_Angeles)")
+	var jobs jobFlags
+	flag.Var(&jobs, "job", "Scheduled job as kind=cron-expression, repeatable; kinds: run, today, settle, gaps, links, enrich, github (replaces -schedule)")
+	gapDays := flag.Int("gap-days", 30, "Number of past days the gaps job scans (default 30)")
 	historical := flag.Bool("historical", false, "If set, run the task for every day from 2016-07-29 to the present day")
 	lastMonth := flag.Bool("last-month", false, "If set, run the task for every day in the previous month")
 	platformParam := flag.String("platform", "producthunt", "Platform to fetch products from (default: producthunt)")
+	settleDays := flag.Int("settle-days", 2, "Number of previous days each scheduled run re-fetches before their rankings are final (default 2)")
+	dryRun := flag.Bool("dry-run", false, "If set, print the changes each date would get instead of writing them")
+	diffFormat := flag.String("diff-format", "text", "Output format of -dry-run: text or json (default text)")
+	listen := flag.String("listen", "", "Address of the health and status HTTP endpoint in -repeat mode, e.g. :9090 (default disabled)")
+	lockWait := flag.Duration("lock-wait", 0, "How long to wait when another receiver is writing the same dates before skipping the run (default 0, skip immediately)")
+	resolveURLs := flag.Bool("resolve-urls", true, "Follow the redirects of new product links before canonicalizing them (default true)")
 	flag.Parse()
 
 	// Validate the date flag if provided.
 	if *dateParam != "" {
 		if _, err := time.Parse("2006-01-02", *dateParam); err != nil {
-			log.Fatalf("Invalid date format for -date flag. Expected YYYY-MM-DD: %v", err)
+			logging.Fatal("Invalid date format for -date flag, expected YYYY-MM-DD", "error", err)
 		}
 	}
 
-	parts := strings.Split(*schedule, ":")
-	if len(parts) != 2 {
-		log.Fatalf("Invalid schedule time format: expected HH:MM")
-	}
-	hour, err := strconv.Atoi(parts[0])
+	loc, err := time.LoadLocation(*timezone)
 	if err != nil {
-		log.Fatalf("Invalid hour in schedule time: %v", err)
+		logging.Fatal("Invalid -timezone", "error", err)
 	}
-	minute, err := strconv.Atoi(parts[1])
-	if err != nil {
-		log.Fatalf("Invalid minute in schedule time: %v", err)
+	location = loc
+	if len(jobs) == 0 {
+		jobs = jobFlags{jobRun + "=" + scheduleToCron(*scheduleParam)}
 	}
 
-	// Load environment variables.
-	err = godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	if *settleDays < 0 {
+		logging.Fatal("Invalid -settle-days: must not be negative")
 	}
 
-	// Initialize the database connection.
-	dbs, err = db.ConnectToDB()
-	if err != nil {
-		log.Fatal(err)
+	if *diffFormat != "text" && *diffFormat != "json" {
+		logging.Fatal("Invalid -diff-format: expected text or json")
 	}
 
-	// Initialize platform client based on platform parameter
-	var platformClient platform.LaunchPlatform
-	switch *platformParam {
-	case "producthunt":
-		apiKey := os.Getenv("PH_API_KEY")
-		if apiKey == "" {
-			log.Fatal("PH_API_KEY environment variable is required for ProductHunt platform")
-		}
-		platformClient = producthunt.NewProductHuntPlatform(apiKey)
-	default:
-		log.Fatalf("Unsupported platform: %s. Supported platforms: producthunt", *platformParam)
+	connect()
+	platformClient, depth := newPlatformClient(*platformParam)
+
+	opts := taskOptions{
+		Depth:       depth,
+		SettleDays:  *settleDays,
+		DryRun:      *dryRun,
+		DiffFormat:  *diffFormat,
+		LockWait:    *lockWait,
+		ResolveURLs: *resolveURLs,
 	}
 
 	// If the historical flag is set, execute the task for each day from 2016-07-29 to today.
 	if *historical {
-		loc, err := time.LoadLocation("America/Los_Angeles")
-		if err != nil {
-			log.Fatalf("Failed to load timezone: %v", err)
-		}
 		startDateStr := "2016-07-29"
-		startDate, err := time.ParseInLocation("2006-01-02", startDateStr, loc)
+		startDate, err := time.ParseInLocation("2006-01-02", startDateStr, location)
 		if err != nil {
-			log.Fatalf("Error parsing start date: %v", err)
+			logging.Fatal("Error parsing start date", "error", err)
 		}
 		// Define the end date as today in the specified time zone.
-		endDate := time.Now().In(loc)
+		endDate := time.Now().In(location)
 
 		// Iterate day by day.
-		for d := startDate; !d.After(endDate); d = d.AddDate(0, 0, 1) {
-			dateStr := d.Format("2006-01-02")
-			log.Printf("Processing date: %s for platform: %s", dateStr, platformClient.GetName())
-			runTaskForDate(platformClient, dateStr)
-
-			time.Sleep(20 * time.Second)
-		}
+		logger, _ := newRunLogger("historical", platformClient.GetName())
+		failed, err := lockedRun(logger, platformClient, dateRange(startDate, endDate), opts, 20*time.Second)
+		finishRun(logger, failed, err)
 		return
 	}
 
 	// If the last-month flag is set, execute the task for each day in the previous month.
 	if *lastMonth {
-		loc, err := time.LoadLocation("America/Los_Angeles")
-		if err != nil {
-			log.Fatalf("Failed to load timezone: %v", err)
-		}
-		now := time.Now().In(loc)
+		now := time.Now().In(location)
 		
 		// Calculate first day of current month
-		firstOfCurrentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		firstOfCurrentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, location)
 		
 		// Calculate first day of last month
 		firstOfLastMonth := firstOfCurrentMonth.AddDate(0, -1, 0)
@@ -200,43 +485,54 @@ func main() {
 		// Calculate last day of last month (first day of current month minus 1 day)
 		lastOfLastMonth := firstOfCurrentMonth.AddDate(0, 0, -1)
 
-		log.Printf("Updating last month (%s to %s) for platform: %s", 
-			firstOfLastMonth.Format("2006-01-02"), 
-			lastOfLastMonth.Format("2006-01-02"),
-			platformClient.GetName())
+		logger, _ := newRunLogger("last-month", platformClient.GetName())
+		logger.Info("Updating last month",
+			"from", firstOfLastMonth.Format("2006-01-02"),
+			"to", lastOfLastMonth.Format("2006-01-02"))
 
-		// Iterate day by day through last month.
-		for d := firstOfLastMonth; !d.After(lastOfLastMonth); d = d.AddDate(0, 0, 1) {
-			dateStr := d.Format("2006-01-02")
-			log.Printf("Processing date: %s for platform: %s", dateStr, platformClient.GetName())
-			runTaskForDate(platformClient, dateStr)
-
-			// Small delay to avoid rate limiting
-			time.Sleep(5 * time.Second)
-		}
-		
-		log.Printf("Finished updating last month's data for platform: %s", platformClient.GetName())
+		// Iterate day by day through last month, with a small delay to avoid rate limiting.
+		failed, err := lockedRun(logger, platformClient, dateRange(firstOfLastMonth, lastOfLastMonth), opts, 5*time.Second)
+		finishRun(logger, failed, err)
+		logger.Info("Finished updating last month")
 		return
 	}
 
-	// Define the task function to run for a specific date.
-	task := func() {
-		var date string
+	// The task dates: a specific date, or today plus the days still inside the settle window.
+	taskDates := func() []string {
 		if *dateParam != "" {
-			date = *dateParam
-		} else {
-			date = getToday()
+			return []string{*dateParam}
 		}
-		runTaskForDate(platformClient, date)
+		return settleDates(getToday(), opts.SettleDays)
 	}
 
 	// Execute the task in either scheduled or single-run mode.
+	// A scheduled receiver keeps running after a failed date and retries it on the next run.
 	if *repeatable {
+		scheduler := schedule.New()
+		for _, definition := range jobs {
+			job, err := newJob(definition, location, platformClient, opts, *gapDays)
+			if err != nil {
+				logging.Fatal("Invalid -job", "error", err)
+			}
+			scheduler.Add(job)
+		}
+
+		if *listen != "" {
+			err = metrics.RegisterFreshness(dbs)
+			if err != nil {
+				logging.Fatal("Failed to register metrics", "error", err)
+			}
+			serveStatus(*listen, statusHandler(scheduler, platformClient, opts))
+		}
+
 		if *runNow {
-			task()
+			trackedRun(jobRun, platformClient, taskDates(), opts, 0)
 		}
-		runAtScheduledTime(task, hour, minute)
+		scheduler.Run(context.Background())
 	} else {
-		task()
+		logger, _ := newRunLogger(jobRun, platformClient.GetName())
+		failed, err := lockedRun(logger, platformClient, taskDates(), opts, 0)
+		finishRun(logger, failed, err)
+		logger.Info("Run finished")
 	}
 }
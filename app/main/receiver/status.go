@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dariubs/huntline/app/logging"
+	"github.com/dariubs/huntline/app/metrics"
+	"github.com/dariubs/huntline/app/platform"
+	"github.com/dariubs/huntline/app/schedule"
+)
+
+// runResult is the outcome of a receiver run, as reported by the status endpoint.
+type runResult struct {
+	Job      string    `json:"job"`
+	Run      string    `json:"run"`
+	Dates    []string  `json:"dates"`
+	Failed   []string  `json:"failed,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Skipped  bool      `json:"skipped,omitempty"`
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+}
+
+// receiverStatus tracks the last run per platform for the status endpoint.
+type receiverStatus struct {
+	mu       sync.Mutex
+	lastRuns map[string]runResult
+	running  map[string]string
+}
+
+var status = &receiverStatus{
+	lastRuns: make(map[string]runResult),
+	running:  make(map[string]string),
+}
+
+// runMu serializes runs within the receiver, so a manual run never overlaps a scheduled one.
+var runMu sync.Mutex
+
+// trackedRun runs lockedRun under runMu, logs its failures and records the result for the status endpoint.
+func trackedRun(job string, platformClient platform.LaunchPlatform, dates []string, opts taskOptions, pause time.Duration) {
+	runMu.Lock()
+	defer runMu.Unlock()
+	heldRun(job, platformClient, dates, opts, pause)
+}
+
+// heldRun is trackedRun for a caller that already holds runMu.
+func heldRun(job string, platformClient platform.LaunchPlatform, dates []string, opts taskOptions, pause time.Duration) {
+	name := platformClient.GetName()
+	logger, runID := newRunLogger(job, name)
+	result := runResult{Job: job, Run: runID, Dates: dates, Started: time.Now()}
+	status.mu.Lock()
+	status.running[name] = job
+	status.mu.Unlock()
+
+	failed, err := lockedRun(logger, platformClient, dates, opts, pause)
+	logRunResult(logger, failed, err)
+
+	result.Failed = failed
+	result.Finished = time.Now()
+	if errors.Is(err, errLocked) {
+		result.Skipped = true
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	status.mu.Lock()
+	delete(status.running, name)
+	status.lastRuns[name] = result
+	status.mu.Unlock()
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		slog.Error("Error writing status response", "error", err)
+	}
+}
+
+// statusHandler returns the HTTP handler of the receiver daemon:
+//
+//	GET  /healthz         liveness
+//	GET  /readyz          readiness: the database answers
+//	GET  /status          next scheduled runs and the last run result per platform
+//	POST /run?date=DATE   run now for DATE (YYYY-MM-DD), or today and the settle window without it
+//	GET  /metrics         Prometheus metrics
+//
+// POST /run needs the bearer token RECEIVER_RUN_TOKEN and is disabled while it is unset.
+func statusHandler(scheduler *schedule.Scheduler, platformClient platform.LaunchPlatform, opts taskOptions) http.Handler {
+	mux := http.NewServeMux()
+	runToken := os.Getenv("RECEIVER_RUN_TOKEN")
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		// The receiver is ready once its database answers, including during the run it starts
+		// with (-run-now) before its scheduler runs
+		sqlDB, err := dbs.DB()
+		if err == nil {
+			ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+			defer cancel()
+			err = sqlDB.PingContext(ctx)
+		}
+		if err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "database unavailable", "error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+	})
+
+	mux.Handle("GET /metrics", metrics.Handler())
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		status.mu.Lock()
+		lastRuns := make(map[string]runResult, len(status.lastRuns))
+		for name, result := range status.lastRuns {
+			lastRuns[name] = result
+		}
+		running := make(map[string]string, len(status.running))
+		for name, job := range status.running {
+			running[name] = job
+		}
+		status.mu.Unlock()
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"platform": platformClient.GetName(),
+			"upcoming": scheduler.Upcoming(),
+			"running":  running,
+			"lastRuns": lastRuns,
+		})
+	})
+
+	mux.HandleFunc("POST /run", func(w http.ResponseWriter, r *http.Request) {
+		if runToken == "" {
+			writeJSON(w, http.StatusForbidden, map[string]string{"error": "manual runs are disabled, set RECEIVER_RUN_TOKEN"})
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(runToken)) != 1 {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+			return
+		}
+
+		dates := settleDates(getToday(), opts.SettleDays)
+		if date := r.URL.Query().Get("date"); date != "" {
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid date, expected YYYY-MM-DD"})
+				return
+			}
+			dates = []string{date}
+		}
+
+		// The run takes over the lock, so a second request can not start another run in between
+		if !runMu.TryLock() {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": "a run is already in progress"})
+			return
+		}
+		go func() {
+			defer runMu.Unlock()
+			heldRun("manual", platformClient, dates, opts, 5*time.Second)
+		}()
+		writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "started", "dates": dates})
+	})
+
+	return mux
+}
+
+// serveStatus starts the receiver's HTTP listener in the background.
+func serveStatus(addr string, handler http.Handler) {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		slog.Info("Status endpoint listening", "addr", addr)
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("Status endpoint failed", "addr", addr, "error", err)
+		}
+	}()
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dariubs/huntline/app/platform/producthunt"
+	"github.com/dariubs/huntline/app/schedule"
+)
+
+func TestReadyz(t *testing.T) {
+	useTestDB(t)
+	// The scheduler has not started yet, as during the -run-now run
+	handler := statusHandler(schedule.New(), producthunt.NewProductHuntPlatform(""), taskOptions{})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("/readyz = %d %s, want 200", w.Code, w.Body)
+	}
+
+	sqlDB, err := dbs.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz with a closed database = %d, want 503", w.Code)
+	}
+}
+
+func TestRunNeedsToken(t *testing.T) {
+	tests := []struct {
+		token         string
+		authorization string
+		want          int
+	}{
+		{"", "Bearer anything", http.StatusForbidden},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Setenv("RECEIVER_RUN_TOKEN", tt.token)
+		handler := statusHandler(schedule.New(), producthunt.NewProductHuntPlatform(""), taskOptions{})
+		req := httptest.NewRequest(http.MethodPost, "/run", nil)
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("token %q, Authorization %q: POST /run = %d, want %d", tt.token, tt.authorization, w.Code, tt.want)
+		}
+	}
+}
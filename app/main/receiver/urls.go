@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/targeturl"
+	"github.com/dariubs/huntline/app/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// resolveWorkers is the number of product links resolved at the same time.
+const resolveWorkers = 4
+
+// urlResolver follows the redirects of the links returned by the platforms.
+var urlResolver = targeturl.Resolver{}
+
+// canonicalizeURLs sets the canonical URL of each product from its raw platform link. A link that
+// was already resolved to the product's own site is not resolved again. With resolve unset, or when
+// a link can not be resolved, the link itself is canonicalized; a link that can not be parsed is
+// kept as is.
+func canonicalizeURLs(ctx context.Context, logger *slog.Logger, products []model.Product, existing []model.Product, resolve bool) {
+	ctx, span := tracing.Start(ctx, "receiver.canonicalizeURLs", attribute.Bool("resolve", resolve))
+	defer span.End()
+
+	known := make(map[string]string, len(existing))
+	for _, product := range existing {
+		// A URL still on the platform means the earlier resolve failed or was skipped, so it is retried
+		if product.RawURL != "" && !targeturl.OnPlatform(product.URL) {
+			known[product.RawURL] = product.URL
+		}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < resolveWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				products[i].URL = canonicalURL(ctx, logger, products[i].RawURL, resolve)
+			}
+		}()
+	}
+
+	resolved := 0
+	for i := range products {
+		if products[i].RawURL == "" {
+			continue
+		}
+		if canonical, ok := known[products[i].RawURL]; ok {
+			products[i].URL = canonical
+			continue
+		}
+		resolved++
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	span.SetAttributes(attribute.Int("resolved", resolved))
+}
+
+// canonicalURL returns the canonical URL of a raw platform link.
+func canonicalURL(ctx context.Context, logger *slog.Logger, raw string, resolve bool) string {
+	var canonical string
+	var err error
+	if resolve {
+		canonical, err = urlResolver.Canonical(ctx, raw)
+	} else {
+		canonical, err = targeturl.Canonicalize(raw)
+	}
+	if err != nil {
+		logger.Debug("Error canonicalizing product URL", "url", raw, "error", err)
+	}
+	if canonical == "" {
+		return raw
+	}
+	return canonical
+}
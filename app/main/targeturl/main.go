@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"net/url"
 	"os"
 	"time"
+
+	"github.com/dariubs/huntline/app/targeturl"
 )
 
 func main() {
@@ -16,50 +18,20 @@ func main() {
 	}
 	originalURL := os.Args[1]
 
-	// Instantiate an HTTP client with a custom redirection policy.
-	// The CheckRedirect function prevents automatic following of redirects,
-	// thereby allowing us to capture and examine the "Location" header.
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-	}
-
-	// Execute an HTTP GET request on the provided URL.
-	response, err := client.Get(originalURL)
+	// Follow the redirects of the URL and canonicalize where it ends up.
+	// If the URL can not be resolved, the URL itself is canonicalized.
+	finalURL, err := targeturl.Resolver{}.Canonical(context.Background(), originalURL)
 	if err != nil {
-		fmt.Printf("Error retrieving URL: %v\n", err)
-		return
-	}
-	defer response.Body.Close()
-
-	// Check if the HTTP response indicates a redirection (3xx status code).
-	if response.StatusCode >= http.StatusMultipleChoices && response.StatusCode < http.StatusBadRequest {
-		// Extract the target URL from the "Location" header.
-		targetURL := response.Header.Get("Location")
-		// Parse the target URL to enable structured manipulation.
-		parsedURL, err := url.Parse(targetURL)
-		if err != nil {
-			fmt.Printf("Error parsing target URL: %v\n", err)
+		fmt.Printf("Error resolving URL: %v\n", err)
+		if finalURL == "" {
 			return
 		}
-
-		// Remove the "ref=producthunt" parameter from the query if present.
-		queryParams := parsedURL.Query()
-		if queryParams.Get("ref") == "producthunt" {
-			queryParams.Del("ref")
-			parsedURL.RawQuery = queryParams.Encode()
-		}
-		finalURL := parsedURL.String()
-		fmt.Printf("The provided URL redirects to: %s\n", finalURL)
-
-		// Check if the target URL is live and print the result.
-		liveStatus := checkURLLive(finalURL)
-		fmt.Println(liveStatus)
-	} else {
-		// Inform the user if no redirection is detected.
-		fmt.Printf("The provided URL does not result in a redirection (Status code: %d).\n", response.StatusCode)
 	}
+	fmt.Printf("The provided URL resolves to: %s\n", finalURL)
+
+	// Check if the target URL is live and print the result.
+	liveStatus := checkURLLive(finalURL)
+	fmt.Println(liveStatus)
 }
 
 // checkURLLive performs a HEAD request to determine if the target URL is accessible.
@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"github.com/dariubs/huntline/app/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+)
+
+// freshnessCollector reports how old the latest ranked date of every platform is, queried at scrape time.
+type freshnessCollector struct {
+	db   *gorm.DB
+	desc *prometheus.Desc
+}
+
+// RegisterFreshness registers the huntline_data_freshness_seconds gauge.
+func RegisterFreshness(db *gorm.DB) error {
+	return prometheus.Register(&freshnessCollector{
+		db: db,
+		desc: prometheus.NewDesc(
+			"huntline_data_freshness_seconds",
+			"Seconds since the start (Pacific Time) of the latest ranked date per platform.",
+			[]string{"platform"}, nil,
+		),
+	})
+}
+
+func (c *freshnessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *freshnessCollector) Collect(ch chan<- prometheus.Metric) {
+	var rows []struct {
+		Platform string
+		Latest   time.Time `gorm:"serializer:date"`
+	}
+	err := c.db.Model(&model.Product{}).Scopes(model.Ranked).
+		Select("platform, MAX(date) AS latest").
+		Group("platform").
+		Scan(&rows).Error
+	if err != nil {
+		slog.Error("Error collecting data freshness", "error", err)
+		return
+	}
+
+	loc := types.SanFranciscoLocation()
+	now := time.Now()
+	for _, row := range rows {
+		latest := time.Date(row.Latest.Year(), row.Latest.Month(), row.Latest.Day(), 0, 0, 0, 0, loc)
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(latest).Seconds(), row.Platform)
+	}
+}
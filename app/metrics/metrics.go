@@ -0,0 +1,80 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	// HTTPRequestDuration observes web server request latency per route
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "huntline_http_request_duration_seconds",
+		Help:    "Latency of HTTP requests by route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// DBQueryDuration observes database statement durations
+	DBQueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "huntline_db_query_duration_seconds",
+		Help:    "Duration of database statements by operation and table.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "table"})
+
+	// FetchDuration observes how long fetching a day from a platform takes
+	FetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "huntline_fetch_duration_seconds",
+		Help:    "Latency of fetching top products from a launch platform.",
+		Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"platform"})
+
+	// FetchErrors counts failed fetches per platform
+	FetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "huntline_fetch_errors_total",
+		Help: "Number of failed fetches from a launch platform.",
+	}, []string{"platform"})
+
+	// ProductsIngested counts products written per platform
+	ProductsIngested = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "huntline_products_ingested_total",
+		Help: "Number of products written to the database.",
+	}, []string{"platform"})
+
+	// LinkChecks counts product website checks per outcome
+	LinkChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "huntline_link_checks_total",
+		Help: "Number of product website checks by status.",
+	}, []string{"status"})
+
+	// OutboundClicks counts the recorded clicks on product links
+	OutboundClicks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "huntline_outbound_clicks_total",
+		Help: "Number of recorded clicks on product links.",
+	})
+)
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// GinMiddleware records the latency of every request under its route pattern.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		// Use the route pattern, not the raw path, to keep the label set small
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		HTTPRequestDuration.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
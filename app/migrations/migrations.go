@@ -0,0 +1,369 @@
+// Package migrations applies the numbered SQL migrations embedded in the binary and records
+// them in the schema_migrations table.
+//
+// A migration is a pair of files NNNN_name.up.sql and NNNN_name.down.sql. Each runs in a
+// transaction together with its schema_migrations row, unless its first line is
+// "-- migrate:no-transaction", which statements such as CREATE INDEX CONCURRENTLY need.
+//
+// Every dialect has its own directory of migrations, postgres and sqlite, numbered alike so a
+// version means the same schema on both.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialects of the migrations, named like the gorm dialectors.
+const (
+	Postgres = "postgres"
+	SQLite   = "sqlite"
+)
+
+// noTransaction marks a migration that must run outside a transaction.
+const noTransaction = "-- migrate:no-transaction"
+
+// lockKey is the advisory lock held while migrating, so two migrators never run at once.
+const lockKey = "huntline:migrate"
+
+// Migration is a numbered schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and when it was applied, if it was.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load returns the embedded migrations of a dialect in version order.
+func Load(dialect string) ([]Migration, error) {
+	if dialect != Postgres && dialect != SQLite {
+		return nil, fmt.Errorf("no migrations for database %q", dialect)
+	}
+	dir := dialect
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %s, expected NNNN_name.up.sql or NNNN_name.down.sql", name)
+		}
+		number, label, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", name)
+		}
+		content, err := fs.ReadFile(files, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if m.Name != label {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d (%s) has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations on a database.
+type Migrator struct {
+	DB *sql.DB
+	// Dialect is Postgres or SQLite
+	Dialect    string
+	Migrations []Migration
+	// Log is told about every migration applied or reverted; nil logs nothing
+	Log func(action string, m Migration)
+}
+
+// New returns a Migrator for the embedded migrations of a dialect.
+func New(db *sql.DB, dialect string) (*Migrator, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Dialect: dialect, Migrations: migrations}, nil
+}
+
+// Up applies the pending migrations up to and including version to, or all of them when to is 0.
+// It returns the number of migrations applied.
+func (m *Migrator) Up(ctx context.Context, to int) (int, error) {
+	applied := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.Migrations {
+			if done[migration.Version] {
+				continue
+			}
+			if to > 0 && migration.Version > to {
+				break
+			}
+			err = m.apply(ctx, conn, migration)
+			if err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations, newest first. It returns the number of
+// migrations reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.Migrations) - 1; i >= 0 && reverted < steps; i-- {
+			if !done[m.Migrations[i].Version] {
+				continue
+			}
+			err = m.revert(ctx, conn, m.Migrations[i])
+			if err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Redo reverts the last applied migration and applies it again, e.g. while writing it. It
+// returns false when no migration is applied.
+func (m *Migrator) Redo(ctx context.Context) (bool, error) {
+	redone := false
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		done, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.Migrations) - 1; i >= 0; i-- {
+			if !done[m.Migrations[i].Version] {
+				continue
+			}
+			err = m.revert(ctx, conn, m.Migrations[i])
+			if err != nil {
+				return err
+			}
+			redone = true
+			return m.apply(ctx, conn, m.Migrations[i])
+		}
+		return nil
+	})
+	return redone, err
+}
+
+// apply runs the up SQL of a migration and records it.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	err := m.run(ctx, conn, migration.Up,
+		m.bind("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+		migration.Version, migration.Name, time.Now())
+	if err != nil {
+		return fmt.Errorf("applying migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	if m.Log != nil {
+		m.Log("applied", migration)
+	}
+	return nil
+}
+
+// revert runs the down SQL of a migration and removes its record.
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("migration %d (%s) has no down file", migration.Version, migration.Name)
+	}
+	err := m.run(ctx, conn, migration.Down, m.bind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+	if err != nil {
+		return fmt.Errorf("reverting migration %d (%s): %w", migration.Version, migration.Name, err)
+	}
+	if m.Log != nil {
+		m.Log("reverted", migration)
+	}
+	return nil
+}
+
+// Status lists every known migration and when it was applied, plus applied versions that this
+// binary does not know, e.g. after a downgrade.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		applied := make(map[int]Status)
+		for rows.Next() {
+			var s Status
+			var at time.Time
+			err = rows.Scan(&s.Version, &s.Name, &at)
+			if err != nil {
+				return err
+			}
+			s.AppliedAt = &at
+			applied[s.Version] = s
+		}
+		err = rows.Err()
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.Migrations {
+			s := Status{Migration: migration}
+			if a, ok := applied[migration.Version]; ok {
+				s.AppliedAt = a.AppliedAt
+				delete(applied, migration.Version)
+			}
+			statuses = append(statuses, s)
+		}
+		for _, s := range applied {
+			statuses = append(statuses, s)
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+		return nil
+	})
+	return statuses, err
+}
+
+// run executes the SQL of a migration and the statement recording it, in one transaction unless
+// the migration opts out.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, script, record string, args ...any) error {
+	if strings.HasPrefix(strings.TrimSpace(script), noTransaction) {
+		_, err := conn.ExecContext(ctx, script)
+		if err != nil {
+			return err
+		}
+		_, err = conn.ExecContext(ctx, record, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, script)
+	if err == nil {
+		_, err = tx.ExecContext(ctx, record, args...)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// bind rewrites the ? placeholders of a query into the $1, $2, ... of Postgres.
+func (m *Migrator) bind(query string) string {
+	if m.Dialect != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// locked runs f on a dedicated connection holding the migration lock, after creating the
+// schema_migrations table if needed. SQLite has no advisory locks; a second migrator of the same
+// file fails on the schema_migrations row of the migration instead, rolling it back.
+func (m *Migrator) locked(ctx context.Context, f func(conn *sql.Conn) error) error {
+	conn, err := m.DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	timestamp := "TIMESTAMPTZ"
+	if m.Dialect == Postgres {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext($1))", lockKey)
+		if err != nil {
+			return err
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext($1))", lockKey)
+	} else {
+		timestamp = "DATETIME"
+	}
+
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at `+timestamp+` NOT NULL
+	)`)
+	if err != nil {
+		return err
+	}
+	return f(conn)
+}
+
+// appliedVersions returns the versions recorded in schema_migrations.
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	versions := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		versions[version] = true
+	}
+	return versions, rows.Err()
+}
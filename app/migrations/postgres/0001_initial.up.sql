@@ -0,0 +1,21 @@
+-- The products table as the original AutoMigrate created it, before any other table existed.
+-- IF NOT EXISTS lets databases created by AutoMigrate adopt it without changes; the later columns,
+-- tables and indexes are added by the following migrations, which skip what AutoMigrate already
+-- created.
+
+CREATE TABLE IF NOT EXISTS products (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    tagline TEXT,
+    description TEXT,
+    rank BIGINT,
+    logo TEXT,
+    date DATE,
+    platform VARCHAR(100) NOT NULL DEFAULT 'producthunt'
+);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_name_date_platform ON products (name, date, platform);
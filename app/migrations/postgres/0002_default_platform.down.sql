@@ -0,0 +1,2 @@
+-- The products the up migration fixed can not be told apart anymore, so there is nothing to undo.
+SELECT 1;
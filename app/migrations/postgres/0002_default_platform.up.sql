@@ -0,0 +1,2 @@
+-- Products stored before platforms existed have no platform; they all came from ProductHunt.
+UPDATE products SET platform = 'producthunt' WHERE platform = '' OR platform IS NULL;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_platform_date_rank;
+DROP INDEX IF EXISTS idx_products_dropped_at;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS dropped_rank,
+    DROP COLUMN IF EXISTS dropped_at;
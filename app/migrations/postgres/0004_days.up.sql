@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS days (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    platform VARCHAR(100) NOT NULL,
+    date DATE NOT NULL,
+    fetched_at TIMESTAMPTZ,
+    final_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_days_deleted_at ON days (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_day_platform_date ON days (platform, date);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    sink VARCHAR(100) NOT NULL,
+    platform VARCHAR(100) NOT NULL,
+    date DATE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_sink_platform_date ON notifications (sink, platform, date);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS subscribers (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    email VARCHAR(255) NOT NULL,
+    frequency VARCHAR(20) NOT NULL,
+    confirm_token VARCHAR(64),
+    unsubscribe_token VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMPTZ,
+    unsubscribed_at TIMESTAMPTZ,
+    last_sent_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_subscribers_deleted_at ON subscribers (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscribers_email ON subscribers (email);
+CREATE INDEX IF NOT EXISTS idx_subscribers_confirm_token ON subscribers (confirm_token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscribers_unsubscribe_token ON subscribers (unsubscribe_token);
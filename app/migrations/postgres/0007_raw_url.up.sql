@@ -0,0 +1,3 @@
+-- The link the platform returned; url holds the canonical URL it resolves to.
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS raw_url TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS link_checks;
+DROP TABLE IF EXISTS links;
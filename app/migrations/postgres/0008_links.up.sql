@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS links (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    url TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    status_code BIGINT,
+    target TEXT,
+    error TEXT,
+    failures BIGINT,
+    first_checked_at TIMESTAMPTZ,
+    checked_at TIMESTAMPTZ,
+    up_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_links_deleted_at ON links (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_url ON links (url);
+CREATE INDEX IF NOT EXISTS idx_links_checked_at ON links (checked_at);
+
+CREATE TABLE IF NOT EXISTS link_checks (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    url TEXT NOT NULL,
+    checked_at TIMESTAMPTZ NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    status_code BIGINT,
+    target TEXT,
+    error TEXT,
+    latency_ms BIGINT
+);
+CREATE INDEX IF NOT EXISTS idx_link_checks_deleted_at ON link_checks (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_link_checks_url ON link_checks (url);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_products_identity_id;
+DROP INDEX IF EXISTS idx_products_domain;
+ALTER TABLE products
+    DROP COLUMN IF EXISTS identity_id,
+    DROP COLUMN IF EXISTS domain;
+DROP TABLE IF EXISTS identity_overrides;
+DROP TABLE IF EXISTS identities;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS identities (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    key VARCHAR(255) NOT NULL,
+    name VARCHAR(255)
+);
+CREATE INDEX IF NOT EXISTS idx_identities_deleted_at ON identities (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_key ON identities (key);
+
+CREATE TABLE IF NOT EXISTS identity_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    key VARCHAR(255) NOT NULL,
+    target VARCHAR(255) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_identity_overrides_deleted_at ON identity_overrides (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_overrides_key ON identity_overrides (key);
+
+ALTER TABLE products
+    ADD COLUMN IF NOT EXISTS domain VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS identity_id BIGINT;
+CREATE INDEX IF NOT EXISTS idx_products_domain ON products (domain);
+CREATE INDEX IF NOT EXISTS idx_products_identity_id ON products (identity_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS site_metadata (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    url TEXT NOT NULL,
+    title VARCHAR(255),
+    description TEXT,
+    image TEXT,
+    favicon TEXT,
+    fetched_at TIMESTAMPTZ,
+    status_code BIGINT,
+    error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_site_metadata_deleted_at ON site_metadata (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_metadata_url ON site_metadata (url);
+CREATE INDEX IF NOT EXISTS idx_site_metadata_fetched_at ON site_metadata (fetched_at);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS repositories (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    key VARCHAR(255) NOT NULL,
+    full_name VARCHAR(255),
+    stars BIGINT,
+    forks BIGINT,
+    license VARCHAR(100),
+    language VARCHAR(100),
+    archived BOOLEAN,
+    last_commit_at TIMESTAMPTZ,
+    fetched_at TIMESTAMPTZ,
+    status_code BIGINT,
+    error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_repositories_deleted_at ON repositories (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_repositories_key ON repositories (key);
+CREATE INDEX IF NOT EXISTS idx_repositories_language ON repositories (language);
+CREATE INDEX IF NOT EXISTS idx_repositories_fetched_at ON repositories (fetched_at);
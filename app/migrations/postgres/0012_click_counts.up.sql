@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS click_counts (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    deleted_at TIMESTAMPTZ,
+    product_id BIGINT NOT NULL,
+    day DATE NOT NULL,
+    clicks BIGINT NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_click_counts_deleted_at ON click_counts (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_click_product_day ON click_counts (product_id, day);
+CREATE INDEX IF NOT EXISTS idx_click_counts_day ON click_counts (day);
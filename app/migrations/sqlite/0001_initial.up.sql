@@ -0,0 +1,19 @@
+-- The products table of the Postgres baseline in SQLite types. Dates are stored as YYYY-MM-DD
+-- text, see model.DateSerializer, so they compare and group like Postgres dates.
+
+CREATE TABLE IF NOT EXISTS products (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    tagline TEXT,
+    description TEXT,
+    rank INTEGER,
+    logo TEXT,
+    date DATE,
+    platform TEXT NOT NULL DEFAULT 'producthunt'
+);
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at ON products (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_name_date_platform ON products (name, date, platform);
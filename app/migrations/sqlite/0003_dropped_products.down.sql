@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_platform_date_rank;
+DROP INDEX IF EXISTS idx_products_dropped_at;
+ALTER TABLE products DROP COLUMN dropped_rank;
+ALTER TABLE products DROP COLUMN dropped_at;
@@ -0,0 +1,17 @@
+-- Products that fall out of their top list are kept as history, see model.ReplaceDay.
+ALTER TABLE products ADD COLUMN dropped_at DATETIME;
+ALTER TABLE products ADD COLUMN dropped_rank INTEGER;
+CREATE INDEX IF NOT EXISTS idx_products_dropped_at ON products (dropped_at);
+
+-- Products saved one by one could end up sharing a rank with the product that took their place.
+-- Keep the most recently updated of them and mark the others dropped, so that every rank of a
+-- platform and date is held by one live product.
+UPDATE products SET dropped_at = CURRENT_TIMESTAMP, dropped_rank = rank
+WHERE dropped_at IS NULL AND deleted_at IS NULL AND EXISTS (
+    SELECT 1 FROM products newer
+    WHERE newer.platform = products.platform AND newer.date = products.date AND newer.rank = products.rank
+        AND newer.dropped_at IS NULL AND newer.deleted_at IS NULL
+        AND (newer.updated_at > products.updated_at OR (newer.updated_at = products.updated_at AND newer.id > products.id))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_platform_date_rank ON products (platform, date, rank)
+    WHERE dropped_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS days (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    platform TEXT NOT NULL,
+    date DATE NOT NULL,
+    fetched_at DATETIME,
+    final_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_days_deleted_at ON days (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_day_platform_date ON days (platform, date);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    sink TEXT NOT NULL,
+    platform TEXT NOT NULL,
+    date DATE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_deleted_at ON notifications (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notification_sink_platform_date ON notifications (sink, platform, date);
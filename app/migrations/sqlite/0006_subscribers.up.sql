@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS subscribers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    email TEXT NOT NULL,
+    frequency TEXT NOT NULL,
+    confirm_token TEXT,
+    unsubscribe_token TEXT NOT NULL,
+    confirmed_at DATETIME,
+    unsubscribed_at DATETIME,
+    last_sent_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_subscribers_deleted_at ON subscribers (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscribers_email ON subscribers (email);
+CREATE INDEX IF NOT EXISTS idx_subscribers_confirm_token ON subscribers (confirm_token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_subscribers_unsubscribe_token ON subscribers (unsubscribe_token);
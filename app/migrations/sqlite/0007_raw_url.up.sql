@@ -0,0 +1,2 @@
+-- The link the platform returned; url holds the canonical URL it resolves to.
+ALTER TABLE products ADD COLUMN raw_url TEXT;
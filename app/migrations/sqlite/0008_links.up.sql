@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    url TEXT NOT NULL,
+    status TEXT NOT NULL,
+    status_code INTEGER,
+    target TEXT,
+    error TEXT,
+    failures INTEGER,
+    first_checked_at DATETIME,
+    checked_at DATETIME,
+    up_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_links_deleted_at ON links (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_url ON links (url);
+CREATE INDEX IF NOT EXISTS idx_links_checked_at ON links (checked_at);
+
+CREATE TABLE IF NOT EXISTS link_checks (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    url TEXT NOT NULL,
+    checked_at DATETIME NOT NULL,
+    status TEXT NOT NULL,
+    status_code INTEGER,
+    target TEXT,
+    error TEXT,
+    latency_ms INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_link_checks_deleted_at ON link_checks (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_link_checks_url ON link_checks (url);
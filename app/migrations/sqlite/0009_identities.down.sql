@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_products_identity_id;
+DROP INDEX IF EXISTS idx_products_domain;
+ALTER TABLE products DROP COLUMN identity_id;
+ALTER TABLE products DROP COLUMN domain;
+DROP TABLE IF EXISTS identity_overrides;
+DROP TABLE IF EXISTS identities;
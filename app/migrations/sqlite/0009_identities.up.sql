@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS identities (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    key TEXT NOT NULL,
+    name TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_identities_deleted_at ON identities (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identities_key ON identities (key);
+
+CREATE TABLE IF NOT EXISTS identity_overrides (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    key TEXT NOT NULL,
+    target TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_identity_overrides_deleted_at ON identity_overrides (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_identity_overrides_key ON identity_overrides (key);
+
+ALTER TABLE products ADD COLUMN domain TEXT;
+ALTER TABLE products ADD COLUMN identity_id INTEGER;
+CREATE INDEX IF NOT EXISTS idx_products_domain ON products (domain);
+CREATE INDEX IF NOT EXISTS idx_products_identity_id ON products (identity_id);
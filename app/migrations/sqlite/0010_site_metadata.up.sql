@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS site_metadata (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    url TEXT NOT NULL,
+    title TEXT,
+    description TEXT,
+    image TEXT,
+    favicon TEXT,
+    fetched_at DATETIME,
+    status_code INTEGER,
+    error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_site_metadata_deleted_at ON site_metadata (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_site_metadata_url ON site_metadata (url);
+CREATE INDEX IF NOT EXISTS idx_site_metadata_fetched_at ON site_metadata (fetched_at);
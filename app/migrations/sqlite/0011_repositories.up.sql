@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS repositories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    key TEXT NOT NULL,
+    full_name TEXT,
+    stars INTEGER,
+    forks INTEGER,
+    license TEXT,
+    language TEXT,
+    archived BOOLEAN,
+    last_commit_at DATETIME,
+    fetched_at DATETIME,
+    status_code INTEGER,
+    error TEXT
+);
+CREATE INDEX IF NOT EXISTS idx_repositories_deleted_at ON repositories (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_repositories_key ON repositories (key);
+CREATE INDEX IF NOT EXISTS idx_repositories_language ON repositories (language);
+CREATE INDEX IF NOT EXISTS idx_repositories_fetched_at ON repositories (fetched_at);
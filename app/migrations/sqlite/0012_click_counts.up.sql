@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS click_counts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    created_at DATETIME,
+    updated_at DATETIME,
+    deleted_at DATETIME,
+    product_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    clicks INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS idx_click_counts_deleted_at ON click_counts (deleted_at);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_click_product_day ON click_counts (product_id, day);
+CREATE INDEX IF NOT EXISTS idx_click_counts_day ON click_counts (day);
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClickCount is the number of outbound clicks on a product's link on one day. Only the count is
+// kept, nothing about the visitors.
+type ClickCount struct {
+	gorm.Model
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_click_product_day"`
+	Day       time.Time `gorm:"serializer:date;type:date;not null;uniqueIndex:idx_click_product_day;index"`
+	Clicks    int64     `gorm:"not null;default:0"`
+}
+
+// ClickedProduct is a product with its clicks over a period.
+type ClickedProduct struct {
+	Product
+	Clicks int64
+}
+
+// RecordClick counts a click on the link of a product on day (YYYY-MM-DD).
+func RecordClick(db *gorm.DB, productID uint, day string) error {
+	date, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"clicks": gorm.Expr("click_counts.clicks + 1"), "updated_at": time.Now()}),
+	}).Create(&ClickCount{ProductID: productID, Day: date, Clicks: 1}).Error
+}
+
+// MostClicked returns the products with the most clicks from day since (YYYY-MM-DD) on, most
+// clicked first.
+func MostClicked(db *gorm.DB, since string, limit int) ([]ClickedProduct, error) {
+	var counts []struct {
+		ProductID uint
+		Clicks    int64
+	}
+	err := db.Model(&ClickCount{}).
+		Select("product_id, SUM(clicks) AS clicks").
+		Where("day >= ?", since).
+		Group("product_id").
+		Order("clicks DESC, product_id ASC").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil || len(counts) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, len(counts))
+	for i, count := range counts {
+		ids[i] = count.ProductID
+	}
+	var products []Product
+	err = db.Where("id IN ?", ids).Find(&products).Error
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	// Deleted products keep their counts but drop out of the ranking
+	ranking := make([]ClickedProduct, 0, len(counts))
+	for _, count := range counts {
+		if product, ok := byID[count.ProductID]; ok {
+			ranking = append(ranking, ClickedProduct{Product: product, Clicks: count.Clicks})
+		}
+	}
+	return ranking, nil
+}
@@ -0,0 +1,56 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// dateLayout is how dates are written to the database.
+const dateLayout = "2006-01-02"
+
+// DateSerializer stores a time.Time as the calendar date it falls on, YYYY-MM-DD, and reads it
+// back as midnight UTC. Postgres casts the text to its date type; SQLite has no date type and
+// keeps the text, which then compares, groups and matches date parameters like a Postgres date.
+// Fields and scanned rows holding a date column use it with the tag `gorm:"serializer:date"`.
+type DateSerializer struct{}
+
+func init() {
+	schema.RegisterSerializer("date", DateSerializer{})
+}
+
+// Scan reads a date as Postgres returns it, a time.Time, or as SQLite does, text.
+func (DateSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var date time.Time
+	switch value := dbValue.(type) {
+	case nil:
+	case time.Time:
+		date = time.Date(value.Year(), value.Month(), value.Day(), 0, 0, 0, 0, time.UTC)
+	case string, []byte:
+		text := fmt.Sprintf("%s", value)
+		if len(text) < len(dateLayout) {
+			return fmt.Errorf("invalid date %q", text)
+		}
+		// Rows written before dates were stored as text may carry a time of day
+		var err error
+		date, err = time.Parse(dateLayout, text[:len(dateLayout)])
+		if err != nil {
+			return fmt.Errorf("invalid date %q: %w", text, err)
+		}
+	default:
+		return fmt.Errorf("unsupported date value %T", dbValue)
+	}
+	return field.Set(ctx, dst, date)
+}
+
+// Value writes the date of a time.Time in its own location.
+func (DateSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	date, ok := fieldValue.(time.Time)
+	if !ok {
+		return nil, fmt.Errorf("unsupported date field %T", fieldValue)
+	}
+	return date.Format(dateLayout), nil
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Day records when a platform's rankings for a date were last fetched and
+// when they became final. Rankings keep moving for a while after the day ends,
+// so a day stays provisional until its settle window has elapsed.
+type Day struct {
+	gorm.Model
+	Platform  string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_day_platform_date"`
+	Date      time.Time `gorm:"serializer:date;type:date;not null;uniqueIndex:idx_day_platform_date"`
+	FetchedAt time.Time
+	FinalAt   *time.Time
+}
+
+// Save records a fetch of the day. Once a day is final it stays final.
+func (day *Day) Save(db *gorm.DB) error {
+	day.Date = normalizeDate(day.Date)
+
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "platform"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"fetched_at": day.FetchedAt,
+			"final_at":   gorm.Expr("COALESCE(days.final_at, ?)", day.FinalAt),
+		}),
+	}).Create(day).Error
+}
+
+// DayKey identifies a platform's day in the map returned by FinalDays.
+func DayKey(platform string, date time.Time) string {
+	return platform + "/" + date.Format("2006-01-02")
+}
+
+// FinalDays returns the final days between from and to (inclusive, YYYY-MM-DD), keyed by DayKey.
+func FinalDays(db *gorm.DB, from, to string) (map[string]bool, error) {
+	var days []Day
+	err := db.Where("date >= ? AND date <= ? AND final_at IS NOT NULL", from, to).Find(&days).Error
+	if err != nil {
+		return nil, err
+	}
+
+	final := make(map[string]bool, len(days))
+	for _, day := range days {
+		final[DayKey(day.Platform, day.Date)] = true
+	}
+	return final, nil
+}
@@ -0,0 +1,233 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/dariubs/huntline/app/targeturl"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Identity is a product across launch platforms. Launches are linked to it by the domain key of
+// their canonical URL (see targeturl.DomainKey), so the same site launched on several platforms,
+// or launched again later, shares one identity.
+type Identity struct {
+	gorm.Model
+	Key  string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Name string `gorm:"type:varchar(255)"`
+}
+
+// IdentityOverride links the launches of a domain key to the identity of another key, for
+// products that moved or run on several domains.
+type IdentityOverride struct {
+	gorm.Model
+	Key    string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Target string `gorm:"type:varchar(255);not null"`
+}
+
+// identityKey returns the identity key of a domain key, applying its override.
+func identityKey(db *gorm.DB, domain string) (string, error) {
+	var override IdentityOverride
+	err := db.Where("key = ?", domain).Limit(1).Find(&override).Error
+	if err != nil {
+		return "", err
+	}
+	if override.ID != 0 {
+		return override.Target, nil
+	}
+	return domain, nil
+}
+
+// ensureIdentity returns the ID of the identity of a domain key, creating it if needed.
+func ensureIdentity(db *gorm.DB, domain, name string) (uint, error) {
+	key, err := identityKey(db, domain)
+	if err != nil {
+		return 0, err
+	}
+	identity := Identity{Key: key, Name: name}
+	err = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&identity).Error
+	if err != nil {
+		return 0, err
+	}
+	if identity.ID == 0 {
+		err = db.Where("key = ?", key).First(&identity).Error
+		if err != nil {
+			return 0, err
+		}
+	}
+	return identity.ID, nil
+}
+
+// AssignIdentities sets the domain key and identity of each product with a canonical URL.
+// Products stored before their links were canonicalized, and products whose link could not be
+// resolved off the platform, point at the platform and are left without an identity.
+func AssignIdentities(db *gorm.DB, products []Product) error {
+	ids := make(map[string]uint)
+	for i := range products {
+		if products[i].RawURL == "" {
+			continue
+		}
+		domain := targeturl.DomainKey(products[i].URL)
+		if domain == "" {
+			products[i].Domain = ""
+			products[i].IdentityID = nil
+			continue
+		}
+		id, ok := ids[domain]
+		if !ok {
+			var err error
+			id, err = ensureIdentity(db, domain, products[i].Name)
+			if err != nil {
+				return fmt.Errorf("identity of %s: %w", domain, err)
+			}
+			ids[domain] = id
+		}
+		products[i].Domain = domain
+		products[i].IdentityID = &id
+	}
+	return nil
+}
+
+// LinkIdentities assigns identities to the stored products that have a canonical URL but no
+// identity yet, and returns the number of products linked. Products linked by the domain key of a
+// launch platform, before those were skipped, are unlinked or linked to their own site.
+func LinkIdentities(db *gorm.DB) (int, error) {
+	var products []Product
+	err := db.Where("raw_url <> '' AND (identity_id IS NULL OR domain IN ?)", targeturl.PlatformHosts()).
+		Find(&products).Error
+	if err != nil {
+		return 0, err
+	}
+	linkedBefore := make([]bool, len(products))
+	for i := range products {
+		linkedBefore[i] = products[i].IdentityID != nil
+	}
+	err = AssignIdentities(db, products)
+	if err != nil {
+		return 0, err
+	}
+
+	linked := 0
+	for i, product := range products {
+		if product.IdentityID == nil && !linkedBefore[i] {
+			continue
+		}
+		err = db.Model(&product).Updates(map[string]interface{}{
+			"domain":      product.Domain,
+			"identity_id": product.IdentityID,
+		}).Error
+		if err != nil {
+			return linked, err
+		}
+		if product.IdentityID != nil {
+			linked++
+		}
+	}
+	return linked, nil
+}
+
+// relinkDomain moves the products of a domain key to the identity its override now points at.
+func relinkDomain(db *gorm.DB, domain string) error {
+	var product Product
+	err := db.Where("domain = ?", domain).Limit(1).Find(&product).Error
+	if err != nil || product.ID == 0 {
+		return err
+	}
+	id, err := ensureIdentity(db, domain, product.Name)
+	if err != nil {
+		return err
+	}
+	return db.Model(&Product{}).Where("domain = ?", domain).Update("identity_id", id).Error
+}
+
+// SetIdentityOverride links the launches of a domain key to the identity of target and moves
+// the stored launches over.
+func SetIdentityOverride(db *gorm.DB, domain, target string) error {
+	if domain == "" || target == "" || domain == target {
+		return errors.New("an override needs two different domain keys")
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		var chained int64
+		err := tx.Model(&IdentityOverride{}).Where("key = ?", target).Count(&chained).Error
+		if err != nil {
+			return err
+		}
+		if chained > 0 {
+			return fmt.Errorf("%s is itself overridden, link %s to its target instead", target, domain)
+		}
+
+		err = tx.Unscoped().Where("key = ?", domain).Delete(&IdentityOverride{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Create(&IdentityOverride{Key: domain, Target: target}).Error
+		if err != nil {
+			return err
+		}
+		return relinkDomain(tx, domain)
+	})
+}
+
+// DeleteIdentityOverride removes the override of a domain key and moves its stored launches
+// back to the identity of the domain key itself.
+func DeleteIdentityOverride(db *gorm.DB, domain string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("key = ?", domain).Delete(&IdentityOverride{}).Error
+		if err != nil {
+			return err
+		}
+		return relinkDomain(tx, domain)
+	})
+}
+
+// AttachAlsoLaunched sets the other platforms each product with an identity was launched on.
+func AttachAlsoLaunched(db *gorm.DB, products []Product) error {
+	var ids []uint
+	for _, product := range products {
+		if product.IdentityID != nil {
+			ids = append(ids, *product.IdentityID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var rows []struct {
+		IdentityID uint
+		Platform   string
+	}
+	err := db.Model(&Product{}).Scopes(Ranked).
+		Select("DISTINCT identity_id, platform").
+		Where("identity_id IN ?", ids).
+		Order("platform ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	platforms := make(map[uint][]string)
+	for _, row := range rows {
+		platforms[row.IdentityID] = append(platforms[row.IdentityID], row.Platform)
+	}
+	for i := range products {
+		if products[i].IdentityID == nil {
+			continue
+		}
+		for _, platform := range platforms[*products[i].IdentityID] {
+			if platform != products[i].Platform {
+				products[i].AlsoLaunchedOn = append(products[i].AlsoLaunchedOn, platform)
+			}
+		}
+	}
+	return nil
+}
+
+// Launches returns the ranked launches of an identity on every platform, newest first.
+func Launches(db *gorm.DB, identityID uint) ([]Product, error) {
+	var products []Product
+	err := db.Scopes(Ranked).Where("identity_id = ?", identityID).
+		Order("date DESC, platform ASC").
+		Find(&products).Error
+	return products, err
+}
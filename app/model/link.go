@@ -0,0 +1,156 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Statuses of a product website.
+const (
+	// LinkUp answers on its own host
+	LinkUp = "up"
+	// LinkMoved redirects to another host
+	LinkMoved = "moved"
+	// LinkDown failed to answer, or answered 404, 410 or a server error, on consecutive checks
+	LinkDown = "down"
+	// LinkUnknown could not be judged, e.g. the site blocks the checker
+	LinkUnknown = "unknown"
+)
+
+// linkDownAfter is the number of consecutive failed checks before a site is shown as down.
+const linkDownAfter = 2
+
+// LinkCheck is one check of a product website.
+type LinkCheck struct {
+	gorm.Model
+	URL        string    `gorm:"type:text;not null;index"`
+	CheckedAt  time.Time `gorm:"not null"`
+	Status     string    `gorm:"type:varchar(20);not null"`
+	StatusCode int
+	// Target is the URL the site redirected to, if any
+	Target    string `gorm:"type:text"`
+	Error     string `gorm:"type:text"`
+	LatencyMS int64
+}
+
+// Link is the current status of a product website, derived from its checks.
+type Link struct {
+	gorm.Model
+	URL        string `gorm:"type:text;not null;uniqueIndex"`
+	Status     string `gorm:"type:varchar(20);not null"`
+	StatusCode int
+	Target     string `gorm:"type:text"`
+	Error      string `gorm:"type:text"`
+	// Failures counts the consecutive failed checks
+	Failures       int
+	FirstCheckedAt time.Time
+	CheckedAt      time.Time `gorm:"index"`
+	// UpAt is the last time the site answered on its own host
+	UpAt *time.Time
+}
+
+// RecordLinkCheck stores a check and updates the status of its website. A failing site is only
+// marked down after linkDownAfter consecutive failures, so a short outage does not flag it.
+func RecordLinkCheck(db *gorm.DB, check *LinkCheck) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(check).Error
+		if err != nil {
+			return err
+		}
+
+		var link Link
+		err = tx.Where("url = ?", check.URL).First(&link).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if link.ID == 0 {
+			link.URL = check.URL
+			link.Status = LinkUnknown
+			link.FirstCheckedAt = check.CheckedAt
+		}
+
+		link.StatusCode = check.StatusCode
+		link.Target = check.Target
+		link.Error = check.Error
+		link.CheckedAt = check.CheckedAt
+		switch check.Status {
+		case LinkUp, LinkMoved:
+			link.Status = check.Status
+			link.Failures = 0
+			if check.Status == LinkUp {
+				link.UpAt = &check.CheckedAt
+			}
+		case LinkDown:
+			link.Failures++
+			if link.Failures >= linkDownAfter {
+				link.Status = LinkDown
+			}
+		}
+		return tx.Save(&link).Error
+	})
+}
+
+// LinksToCheck returns the websites of ranked products that were never checked or not since
+// checkedBefore, least recently checked first. Only canonical URLs are checked; products stored
+// before their links were canonicalized still point at the platform. A limit of 0 returns all.
+func LinksToCheck(db *gorm.DB, checkedBefore time.Time, limit int) ([]string, error) {
+	query := db.Model(&Product{}).Scopes(Ranked).
+		Select("products.url").
+		Joins("LEFT JOIN links ON links.url = products.url AND links.deleted_at IS NULL").
+		Where("products.raw_url <> '' AND products.url <> ''").
+		Where("links.id IS NULL OR links.checked_at < ?", checkedBefore).
+		Group("products.url").
+		Order("MAX(links.checked_at) IS NOT NULL, MAX(links.checked_at) ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var urls []string
+	err := query.Pluck("products.url", &urls).Error
+	return urls, err
+}
+
+// AttachLinks sets the website status of each product that has been checked.
+func AttachLinks(db *gorm.DB, products []Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	urls := make([]string, 0, len(products))
+	for _, product := range products {
+		urls = append(urls, product.URL)
+	}
+
+	var links []Link
+	err := db.Where("url IN ?", urls).Find(&links).Error
+	if err != nil {
+		return err
+	}
+	byURL := make(map[string]*Link, len(links))
+	for i := range links {
+		byURL[links[i].URL] = &links[i]
+	}
+	for i := range products {
+		products[i].Link = byURL[products[i].URL]
+	}
+	return nil
+}
+
+// AliveAfterYear counts the checked websites of products launched more than a year before now,
+// and how many of them are still up.
+func AliveAfterYear(db *gorm.DB, now time.Time) (alive, total int64, err error) {
+	launched := db.Model(&Product{}).Scopes(Ranked).
+		Select("url").
+		Where("raw_url <> '' AND date <= ?", now.AddDate(-1, 0, 0).Format("2006-01-02"))
+
+	var row struct {
+		Total int64
+		Alive int64
+	}
+	err = db.Model(&Link{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS alive", LinkUp).
+		Where("status <> ? AND url IN (?)", LinkUnknown, launched).
+		Scan(&row).Error
+	return row.Alive, row.Total, err
+}
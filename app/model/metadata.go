@@ -0,0 +1,92 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SiteMetadata is what a product's website says about itself in its OpenGraph, Twitter card and
+// HTML head tags. It is kept apart from the platform's data of the product and refreshed from the
+// site on a schedule.
+type SiteMetadata struct {
+	gorm.Model
+	URL         string    `gorm:"type:text;not null;uniqueIndex"`
+	Title       string    `gorm:"type:varchar(255)"`
+	Description string    `gorm:"type:text"`
+	Image       string    `gorm:"type:text"`
+	Favicon     string    `gorm:"type:text"`
+	FetchedAt   time.Time `gorm:"index"`
+	StatusCode  int
+	// Error is why the last fetch failed; the metadata of the fetch before it is kept
+	Error string `gorm:"type:text"`
+}
+
+// TableName keeps the table name stable, "metadata" has no plural.
+func (SiteMetadata) TableName() string {
+	return "site_metadata"
+}
+
+// SaveSiteMetadata records a fetch of a website's metadata. A failed fetch only records its
+// error, keeping the metadata of the last successful one.
+func SaveSiteMetadata(db *gorm.DB, md *SiteMetadata) error {
+	updates := map[string]interface{}{
+		"fetched_at":  md.FetchedAt,
+		"status_code": md.StatusCode,
+		"error":       md.Error,
+	}
+	if md.Error == "" {
+		updates["title"] = md.Title
+		updates["description"] = md.Description
+		updates["image"] = md.Image
+		updates["favicon"] = md.Favicon
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "url"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(md).Error
+}
+
+// MetadataToFetch returns the websites of ranked products whose metadata was never fetched or not
+// since fetchedBefore, least recently fetched first. A limit of 0 returns all.
+func MetadataToFetch(db *gorm.DB, fetchedBefore time.Time, limit int) ([]string, error) {
+	query := db.Model(&Product{}).Scopes(Ranked).
+		Joins("LEFT JOIN site_metadata ON site_metadata.url = products.url AND site_metadata.deleted_at IS NULL").
+		Where("products.raw_url <> '' AND products.url <> ''").
+		Where("site_metadata.id IS NULL OR site_metadata.fetched_at < ?", fetchedBefore).
+		Group("products.url").
+		Order("MAX(site_metadata.fetched_at) IS NOT NULL, MAX(site_metadata.fetched_at) ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var urls []string
+	err := query.Pluck("products.url", &urls).Error
+	return urls, err
+}
+
+// AttachMetadata sets the website metadata of each product that has been fetched.
+func AttachMetadata(db *gorm.DB, products []Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	urls := make([]string, 0, len(products))
+	for _, product := range products {
+		urls = append(urls, product.URL)
+	}
+
+	var metadata []SiteMetadata
+	err := db.Where("url IN ?", urls).Find(&metadata).Error
+	if err != nil {
+		return err
+	}
+	byURL := make(map[string]*SiteMetadata, len(metadata))
+	for i := range metadata {
+		byURL[metadata[i].URL] = &metadata[i]
+	}
+	for i := range products {
+		products[i].Metadata = byURL[products[i].URL]
+	}
+	return nil
+}
@@ -0,0 +1,379 @@
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/migrations"
+	"gorm.io/gorm"
+)
+
+// testDB opens a new SQLite database migrated to the latest version.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb, _ := openTestDB(t)
+	return gdb
+}
+
+// openTestDB opens a new SQLite database with the migrations applied and returns its migrator.
+func openTestDB(t *testing.T) (*gorm.DB, *migrations.Migrator) {
+	t.Helper()
+	gdb, err := db.Open(db.Config{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "huntline.db")})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	m, err := migrations.New(sqlDB, migrations.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Up(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return gdb, m
+}
+
+// launchDate is a day of ProductHunt rankings, midnight in Pacific Time like the platform returns it.
+func launchDate(t *testing.T, date string) time.Time {
+	t.Helper()
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// ranking builds the products of a day from their names in rank order.
+func ranking(date time.Time, names ...string) []Product {
+	products := make([]Product, len(names))
+	for i, name := range names {
+		products[i] = Product{Name: name, URL: "https://" + name + ".example.com/", Rank: uint(i + 1), Date: date, Platform: "producthunt"}
+	}
+	return products
+}
+
+// ranks returns the ranks of the ranked products of a date by name, and the last rank of the
+// dropped ones.
+func ranks(t *testing.T, gdb *gorm.DB, date string) (ranked, dropped map[string]uint) {
+	t.Helper()
+	var products []Product
+	err := gdb.Where("platform = ? AND date = ?", "producthunt", date).Find(&products).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	ranked, dropped = map[string]uint{}, map[string]uint{}
+	for _, p := range products {
+		if p.DroppedAt == nil {
+			ranked[p.Name] = p.Rank
+		} else {
+			dropped[p.Name] = p.DroppedRank
+		}
+	}
+	return ranked, dropped
+}
+
+func equalRanks(a, b map[string]uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMigrations(t *testing.T) {
+	ctx := context.Background()
+	gdb, m := openTestDB(t)
+
+	models := []interface{}{&Product{}, &Day{}, &Notification{}, &Subscriber{}, &Link{}, &LinkCheck{},
+		&Identity{}, &IdentityOverride{}, &SiteMetadata{}, &Repository{}, &ClickCount{}}
+	for _, model := range models {
+		if !gdb.Migrator().HasTable(model) {
+			t.Errorf("table of %T is missing after migrating up", model)
+		}
+	}
+	for _, column := range []string{"RawURL", "DroppedAt", "DroppedRank", "Domain", "IdentityID"} {
+		if !gdb.Migrator().HasColumn(&Product{}, column) {
+			t.Errorf("products.%s is missing after migrating up", column)
+		}
+	}
+
+	reverted, err := m.Down(ctx, len(m.Migrations))
+	if err != nil {
+		t.Fatalf("migrating down: %v", err)
+	}
+	if reverted != len(m.Migrations) {
+		t.Errorf("reverted %d migrations, want %d", reverted, len(m.Migrations))
+	}
+	for _, model := range models {
+		if gdb.Migrator().HasTable(model) {
+			t.Errorf("table of %T is left after migrating down", model)
+		}
+	}
+
+	applied, err := m.Up(ctx, 0)
+	if err != nil {
+		t.Fatalf("migrating up again: %v", err)
+	}
+	if applied != len(m.Migrations) {
+		t.Errorf("applied %d migrations, want %d", applied, len(m.Migrations))
+	}
+}
+
+func TestReplaceDay(t *testing.T) {
+	gdb := testDB(t)
+	date := launchDate(t, "2025-01-15")
+	day := func() *Day { return &Day{Platform: "producthunt", Date: date, FetchedAt: time.Now()} }
+
+	err := ReplaceDay(gdb, day(), ranking(date, "alpha", "beta", "gamma"))
+	if err != nil {
+		t.Fatalf("ReplaceDay: %v", err)
+	}
+
+	// beta falls out of the top list and is kept as history
+	err = ReplaceDay(gdb, day(), ranking(date, "alpha", "gamma", "delta"))
+	if err != nil {
+		t.Fatalf("ReplaceDay: %v", err)
+	}
+	ranked, dropped := ranks(t, gdb, "2025-01-15")
+	if want := map[string]uint{"alpha": 1, "gamma": 2, "delta": 3}; !equalRanks(ranked, want) {
+		t.Errorf("ranked %v, want %v", ranked, want)
+	}
+	if want := map[string]uint{"beta": 2}; !equalRanks(dropped, want) {
+		t.Errorf("dropped %v, want %v", dropped, want)
+	}
+
+	// and is ranked again when it climbs back
+	err = ReplaceDay(gdb, day(), ranking(date, "alpha", "beta", "gamma"))
+	if err != nil {
+		t.Fatalf("ReplaceDay: %v", err)
+	}
+	ranked, dropped = ranks(t, gdb, "2025-01-15")
+	if want := map[string]uint{"alpha": 1, "beta": 2, "gamma": 3}; !equalRanks(ranked, want) {
+		t.Errorf("ranked %v, want %v", ranked, want)
+	}
+	if want := map[string]uint{"delta": 3}; !equalRanks(dropped, want) {
+		t.Errorf("dropped %v, want %v", dropped, want)
+	}
+
+	// Two products on one rank fail the whole day, which keeps its previous ranking
+	broken := ranking(date, "alpha", "epsilon")
+	broken[1].Rank = 1
+	err = ReplaceDay(gdb, day(), broken)
+	if err == nil {
+		t.Fatal("ReplaceDay stored two products on one rank")
+	}
+	after, _ := ranks(t, gdb, "2025-01-15")
+	if !equalRanks(after, ranked) {
+		t.Errorf("failed replace changed the ranking to %v", after)
+	}
+}
+
+func TestReplaceDayLinksIdentities(t *testing.T) {
+	gdb := testDB(t)
+	identities := make(map[string]uint)
+	for _, date := range []string{"2025-01-14", "2025-01-15"} {
+		d := launchDate(t, date)
+		products := ranking(d, "alpha", "beta", "gamma")
+		for i := range products {
+			products[i].RawURL = "https://www.producthunt.com/r/" + products[i].Name
+		}
+		// gamma's link could not be resolved off the platform
+		products[2].URL = products[2].RawURL
+		err := ReplaceDay(gdb, &Day{Platform: "producthunt", Date: d, FetchedAt: time.Now()}, products)
+		if err != nil {
+			t.Fatalf("ReplaceDay: %v", err)
+		}
+		for _, p := range products {
+			if p.IdentityID == nil {
+				identities[date+"/"+p.Name] = 0
+				continue
+			}
+			identities[date+"/"+p.Name] = *p.IdentityID
+		}
+	}
+
+	if identities["2025-01-14/alpha"] == 0 || identities["2025-01-14/alpha"] != identities["2025-01-15/alpha"] {
+		t.Errorf("alpha launched twice has identities %d and %d, want one", identities["2025-01-14/alpha"], identities["2025-01-15/alpha"])
+	}
+	if identities["2025-01-15/beta"] == 0 || identities["2025-01-15/beta"] == identities["2025-01-15/alpha"] {
+		t.Errorf("beta has identity %d, want its own", identities["2025-01-15/beta"])
+	}
+	if identities["2025-01-15/gamma"] != 0 {
+		t.Errorf("gamma on the platform has identity %d, want none", identities["2025-01-15/gamma"])
+	}
+
+	var count int64
+	gdb.Model(&Identity{}).Count(&count)
+	if count != 2 {
+		t.Errorf("stored %d identities, want 2", count)
+	}
+	var stored Product
+	gdb.Where("name = ? AND date = ?", "alpha", "2025-01-15").First(&stored)
+	if stored.IdentityID == nil || *stored.IdentityID != identities["2025-01-15/alpha"] {
+		t.Errorf("stored alpha has identity %v, want %d", stored.IdentityID, identities["2025-01-15/alpha"])
+	}
+}
+
+func TestCountByDate(t *testing.T) {
+	gdb := testDB(t)
+	for date, names := range map[string][]string{
+		"2025-01-14": {"alpha", "beta"},
+		"2025-01-15": {"gamma", "delta", "epsilon"},
+		"2025-01-17": {"zeta"},
+	} {
+		d := launchDate(t, date)
+		err := ReplaceDay(gdb, &Day{Platform: "producthunt", Date: d, FetchedAt: time.Now()}, ranking(d, names...))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Dropped products are not counted
+	d := launchDate(t, "2025-01-15")
+	err := ReplaceDay(gdb, &Day{Platform: "producthunt", Date: d, FetchedAt: time.Now()}, ranking(d, "gamma", "delta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := CountByDate(gdb, "producthunt", "2025-01-14", "2025-01-16")
+	if err != nil {
+		t.Fatalf("CountByDate: %v", err)
+	}
+	want := map[string]int64{"2025-01-14": 2, "2025-01-15": 2}
+	if len(counts) != len(want) || counts["2025-01-14"] != 2 || counts["2025-01-15"] != 2 {
+		t.Errorf("CountByDate = %v, want %v", counts, want)
+	}
+}
+
+func TestFinalDays(t *testing.T) {
+	gdb := testDB(t)
+	finalAt := time.Now()
+	for _, day := range []Day{
+		{Platform: "producthunt", Date: launchDate(t, "2025-01-14"), FetchedAt: finalAt, FinalAt: &finalAt},
+		{Platform: "producthunt", Date: launchDate(t, "2025-01-15"), FetchedAt: finalAt},
+		{Platform: "producthunt", Date: launchDate(t, "2025-01-20"), FetchedAt: finalAt, FinalAt: &finalAt},
+	} {
+		err := day.Save(gdb)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A final day stays final when it is fetched again
+	again := Day{Platform: "producthunt", Date: launchDate(t, "2025-01-14"), FetchedAt: time.Now()}
+	err := again.Save(gdb)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	final, err := FinalDays(gdb, "2025-01-14", "2025-01-16")
+	if err != nil {
+		t.Fatalf("FinalDays: %v", err)
+	}
+	var keys []string
+	for key := range final {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	if len(keys) != 1 || keys[0] != "producthunt/2025-01-14" {
+		t.Errorf("FinalDays = %v, want only producthunt/2025-01-14", keys)
+	}
+	if !final[DayKey("producthunt", launchDate(t, "2025-01-14"))] {
+		t.Error("DayKey of a Pacific Time date does not match FinalDays")
+	}
+}
+
+func TestClaimNotification(t *testing.T) {
+	gdb := testDB(t)
+	date := launchDate(t, "2025-01-15")
+
+	claimed, err := ClaimNotification(gdb, "slack", "producthunt", date)
+	if err != nil || !claimed {
+		t.Fatalf("first claim = %v, %v; want claimed", claimed, err)
+	}
+	claimed, err = ClaimNotification(gdb, "slack", "producthunt", date)
+	if err != nil || claimed {
+		t.Fatalf("second claim = %v, %v; want not claimed", claimed, err)
+	}
+	claimed, err = ClaimNotification(gdb, "discord", "producthunt", date)
+	if err != nil || !claimed {
+		t.Fatalf("claim of another sink = %v, %v; want claimed", claimed, err)
+	}
+
+	// A released notification can be claimed again
+	err = ReleaseNotification(gdb, "slack", "producthunt", date)
+	if err != nil {
+		t.Fatalf("ReleaseNotification: %v", err)
+	}
+	claimed, err = ClaimNotification(gdb, "slack", "producthunt", date)
+	if err != nil || !claimed {
+		t.Fatalf("claim after release = %v, %v; want claimed", claimed, err)
+	}
+}
+
+func TestRecordClick(t *testing.T) {
+	gdb := testDB(t)
+	date := launchDate(t, "2025-01-15")
+	err := ReplaceDay(gdb, &Day{Platform: "producthunt", Date: date, FetchedAt: time.Now()}, ranking(date, "alpha", "beta"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var products []Product
+	err = gdb.Order("rank").Find(&products).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	alpha, beta := products[0].ID, products[1].ID
+
+	for _, click := range []struct {
+		product uint
+		day     string
+	}{
+		{alpha, "2025-01-15"}, {alpha, "2025-01-15"}, {beta, "2025-01-15"},
+		{beta, "2025-01-16"}, {beta, "2025-01-16"}, {alpha, "2025-01-10"},
+	} {
+		err = RecordClick(gdb, click.product, click.day)
+		if err != nil {
+			t.Fatalf("RecordClick: %v", err)
+		}
+	}
+
+	var count ClickCount
+	err = gdb.Where("product_id = ? AND day = ?", alpha, "2025-01-15").First(&count).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count.Clicks != 2 {
+		t.Errorf("alpha has %d clicks on 2025-01-15, want 2", count.Clicks)
+	}
+
+	// Clicks before since are left out
+	clicked, err := MostClicked(gdb, "2025-01-15", 10)
+	if err != nil {
+		t.Fatalf("MostClicked: %v", err)
+	}
+	if len(clicked) != 2 || clicked[0].ID != beta || clicked[0].Clicks != 3 || clicked[1].ID != alpha || clicked[1].Clicks != 2 {
+		t.Errorf("MostClicked = %+v, want beta with 3 clicks, then alpha with 2", clicked)
+	}
+
+	err = RecordClick(gdb, alpha, "15/01/2025")
+	if err == nil {
+		t.Error("RecordClick accepted an invalid day")
+	}
+}
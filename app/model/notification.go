@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Notification records that a platform's top list of a date was posted to a notification sink,
+// so that re-fetching the date does not post it again.
+type Notification struct {
+	gorm.Model
+	Sink     string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_sink_platform_date"`
+	Platform string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_sink_platform_date"`
+	Date     time.Time `gorm:"serializer:date;type:date;not null;uniqueIndex:idx_notification_sink_platform_date"`
+}
+
+// ClaimNotification records the notification unless it already exists and reports whether
+// this call recorded it. Only the caller that claimed a notification sends it.
+func ClaimNotification(db *gorm.DB, sink, platform string, date time.Time) (bool, error) {
+	n := Notification{Sink: sink, Platform: platform, Date: normalizeDate(date)}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&n)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseNotification removes a claimed notification that could not be sent, so that the next fetch retries it.
+func ReleaseNotification(db *gorm.DB, sink, platform string, date time.Time) error {
+	return db.Unscoped().
+		Where("sink = ? AND platform = ? AND date = ?", sink, platform, normalizeDate(date).Format("2006-01-02")).
+		Delete(&Notification{}).Error
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,40 +11,98 @@ import (
 type Product struct {
 	gorm.Model
 	Name        string `gorm:"type:varchar(255);not null;uniqueIndex:idx_name_date_platform"`
+	// URL is the canonical URL of the product's site, RawURL the link the platform returned
 	URL         string `gorm:"type:text;not null"`
+	RawURL      string `gorm:"type:text"`
 	Tagline     string `gorm:"type:text"`
 	Description string `gorm:"type:text"`
-	Rank        uint
+	Rank        uint      `gorm:"uniqueIndex:idx_platform_date_rank,priority:3"`
 	Logo        string    `gorm:"type:text"`
-	Date        time.Time `gorm:"type:date;uniqueIndex:idx_name_date_platform"`
-	Platform    string    `gorm:"type:varchar(100);not null;default:'producthunt';uniqueIndex:idx_name_date_platform"`
+	Date        time.Time `gorm:"serializer:date;type:date;uniqueIndex:idx_name_date_platform;uniqueIndex:idx_platform_date_rank,priority:2"`
+	Platform    string    `gorm:"type:varchar(100);not null;default:'producthunt';uniqueIndex:idx_name_date_platform;uniqueIndex:idx_platform_date_rank,priority:1,where:dropped_at IS NULL AND deleted_at IS NULL"`
+
+	// DroppedAt is set when the product falls out of the platform's top list
+	// for its date. DroppedRank keeps the last rank it held before dropping.
+	DroppedAt   *time.Time `gorm:"index"`
+	DroppedRank uint
+
+	// Domain is the domain key of the canonical URL and IdentityID the product across
+	// platforms it belongs to, see AssignIdentities
+	Domain     string `gorm:"type:varchar(255);index"`
+	IdentityID *uint  `gorm:"index"`
+
+	// Link is the status of the product's website, set by AttachLinks
+	Link *Link `gorm:"-"`
+	// Metadata is what the product's website says about itself, set by AttachMetadata
+	Metadata *SiteMetadata `gorm:"-"`
+	// Repository is the GitHub repository of an open-source product, set by AttachRepositories
+	Repository *Repository `gorm:"-"`
+	// AlsoLaunchedOn lists the other platforms of the product, set by AttachAlsoLaunched
+	AlsoLaunchedOn []string `gorm:"-"`
 }
 
-func (product *Product) Save(db *gorm.DB) error {
-	// Normalize date to midnight in San Francisco timezone to avoid timezone conversion issues
+// Ranked limits a query to products that are still in their platform's top list.
+func Ranked(db *gorm.DB) *gorm.DB {
+	return db.Where("dropped_at IS NULL")
+}
+
+// CountByDate returns the number of ranked products per date (YYYY-MM-DD) for a platform between from and to inclusive.
+func CountByDate(db *gorm.DB, platform, from, to string) (map[string]int64, error) {
+	var rows []struct {
+		Date  time.Time `gorm:"serializer:date"`
+		Count int64
+	}
+	err := db.Model(&Product{}).Scopes(Ranked).
+		Select("date, COUNT(*) AS count").
+		Where("platform = ? AND date >= ? AND date <= ?", platform, from, to).
+		Group("date").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Date.Format("2006-01-02")] = row.Count
+	}
+	return counts, nil
+}
+
+// normalizeDate normalizes a date to midnight in San Francisco timezone to avoid timezone conversion issues
+func normalizeDate(date time.Time) time.Time {
 	loc, err := time.LoadLocation("America/Los_Angeles")
-	if err == nil {
-		// Convert to PST/PDT timezone first, then normalize to midnight
-		dateInLoc := product.Date.In(loc)
-		product.Date = time.Date(dateInLoc.Year(), dateInLoc.Month(), dateInLoc.Day(), 0, 0, 0, 0, loc)
-	} else {
+	if err != nil {
 		// Fallback: truncate to 24 hours if timezone loading fails
-		product.Date = product.Date.Truncate(24 * time.Hour)
+		return date.Truncate(24 * time.Hour)
 	}
-	
+	// Convert to PST/PDT timezone first, then normalize to midnight
+	dateInLoc := date.In(loc)
+	return time.Date(dateInLoc.Year(), dateInLoc.Month(), dateInLoc.Day(), 0, 0, 0, 0, loc)
+}
+
+func (product *Product) Save(db *gorm.DB) error {
+	product.Date = normalizeDate(product.Date)
+
 	// Set default platform if not specified
 	if product.Platform == "" {
 		product.Platform = "producthunt"
 	}
 
-	err = db.Clauses(clause.OnConflict{
+	err := db.Clauses(clause.OnConflict{
 		Columns: []clause.Column{{Name: "name"}, {Name: "date"}, {Name: "platform"}}, // Conflict based on name, date, and platform
 		DoUpdates: clause.Assignments(map[string]interface{}{
 			"rank":        product.Rank,
 			"tagline":     product.Tagline,
 			"url":         product.URL,
+			"raw_url":     product.RawURL,
+			"domain":      product.Domain,
+			// Keep the stored identity if it could not be assigned this time
+			"identity_id": gorm.Expr("COALESCE(?, products.identity_id)", product.IdentityID),
 			"logo":        product.Logo,
 			"description": product.Description,
+			// A product that climbs back into the top list is no longer dropped
+			"dropped_at":   nil,
+			"dropped_rank": 0,
 		}),
 	}).Create(product).Error
 
@@ -53,3 +112,38 @@ func (product *Product) Save(db *gorm.DB) error {
 
 	return nil
 }
+
+// ReplaceDay atomically replaces the ranked products of a platform's day, links them to their
+// identities (see AssignIdentities) and records the fetch. Products that are no longer in the top
+// list are kept as dropped history. If any statement fails, for example because two products share
+// a rank, the whole day is rolled back.
+func ReplaceDay(db *gorm.DB, day *Day, products []Product) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		// Identities created by a concurrent run are found instead of conflicting, see ensureIdentity
+		err := AssignIdentities(tx, products)
+		if err != nil {
+			return fmt.Errorf("linking products across platforms: %w", err)
+		}
+
+		// Move the current rankings out of the way so the new ranks cannot collide with them.
+		// Products still in the top list are un-dropped again by Save.
+		err = tx.Model(&Product{}).Scopes(Ranked).
+			Where("platform = ? AND date = ?", day.Platform, normalizeDate(day.Date).Format("2006-01-02")).
+			Updates(map[string]interface{}{
+				"dropped_at":   day.FetchedAt,
+				"dropped_rank": gorm.Expr("rank"),
+			}).Error
+		if err != nil {
+			return err
+		}
+
+		for i := range products {
+			err = products[i].Save(tx)
+			if err != nil {
+				return fmt.Errorf("saving product %s: %w", products[i].Name, err)
+			}
+		}
+
+		return day.Save(tx)
+	})
+}
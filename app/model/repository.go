@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository holds the GitHub facts of an open-source product. It is keyed by the domain key of
+// the products linking to it, e.g. github.com/owner/name, and refreshed on a schedule.
+type Repository struct {
+	gorm.Model
+	Key string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	// FullName is owner/name as GitHub spells it; it is empty until a fetch succeeds
+	FullName     string `gorm:"type:varchar(255)"`
+	Stars        int
+	Forks        int
+	License      string `gorm:"type:varchar(100)"`
+	Language     string `gorm:"type:varchar(100);index"`
+	Archived     bool
+	LastCommitAt *time.Time
+	FetchedAt    time.Time `gorm:"index"`
+	StatusCode   int
+	// Error is why the last fetch failed; the facts of the fetch before it are kept
+	Error string `gorm:"type:text"`
+}
+
+// StarsLabel returns the star count the way GitHub shows it, e.g. 950 or 12.3k.
+func (r Repository) StarsLabel() string {
+	switch {
+	case r.Stars >= 1000000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(r.Stars)/1000000), ".0") + "m"
+	case r.Stars >= 1000:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(r.Stars)/1000), ".0") + "k"
+	default:
+		return fmt.Sprint(r.Stars)
+	}
+}
+
+// SaveRepository records a fetch of a repository. A failed fetch only records its error,
+// keeping the facts of the last successful one.
+func SaveRepository(db *gorm.DB, repo *Repository) error {
+	updates := map[string]interface{}{
+		"fetched_at":  repo.FetchedAt,
+		"status_code": repo.StatusCode,
+		"error":       repo.Error,
+	}
+	if repo.Error == "" {
+		updates["full_name"] = repo.FullName
+		updates["stars"] = repo.Stars
+		updates["forks"] = repo.Forks
+		updates["license"] = repo.License
+		updates["language"] = repo.Language
+		updates["archived"] = repo.Archived
+		updates["last_commit_at"] = repo.LastCommitAt
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(updates),
+	}).Create(repo).Error
+}
+
+// RepositoriesToFetch returns the domain keys of the GitHub repositories of ranked products that
+// were never fetched or not since fetchedBefore, least recently fetched first. A limit of 0
+// returns all.
+func RepositoriesToFetch(db *gorm.DB, fetchedBefore time.Time, limit int) ([]string, error) {
+	query := db.Model(&Product{}).Scopes(Ranked).
+		Joins("LEFT JOIN repositories ON repositories.key = products.domain AND repositories.deleted_at IS NULL").
+		Where("products.domain LIKE ?", "github.com/%/%").
+		Where("repositories.id IS NULL OR repositories.fetched_at < ?", fetchedBefore).
+		Group("products.domain").
+		Order("MAX(repositories.fetched_at) IS NOT NULL, MAX(repositories.fetched_at) ASC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var keys []string
+	err := query.Pluck("products.domain", &keys).Error
+	return keys, err
+}
+
+// OpenSource limits a query of products to those with a fetched GitHub repository, written
+// mostly in language unless it is empty.
+func OpenSource(language string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Joins("JOIN repositories ON repositories.key = products.domain AND repositories.deleted_at IS NULL AND repositories.full_name <> ''")
+		if language != "" {
+			db = db.Where("LOWER(repositories.language) = LOWER(?)", language)
+		}
+		return db
+	}
+}
+
+// RepositoryLanguages returns the most common languages of the fetched repositories, most common first.
+func RepositoryLanguages(db *gorm.DB, limit int) ([]string, error) {
+	var languages []string
+	err := db.Model(&Repository{}).
+		Where("full_name <> '' AND language <> ''").
+		Group("language").
+		Order("COUNT(*) DESC, language ASC").
+		Limit(limit).
+		Pluck("language", &languages).Error
+	return languages, err
+}
+
+// AttachRepositories sets the GitHub repository of each product that links to a fetched one.
+func AttachRepositories(db *gorm.DB, products []Product) error {
+	var keys []string
+	for _, product := range products {
+		if strings.HasPrefix(product.Domain, "github.com/") {
+			keys = append(keys, product.Domain)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	var repos []Repository
+	err := db.Where("key IN ? AND full_name <> ''", keys).Find(&repos).Error
+	if err != nil {
+		return err
+	}
+	byKey := make(map[string]*Repository, len(repos))
+	for i := range repos {
+		byKey[repos[i].Key] = &repos[i]
+	}
+	for i := range products {
+		products[i].Repository = byKey[products[i].Domain]
+	}
+	return nil
+}
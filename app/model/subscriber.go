@@ -0,0 +1,162 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Digest frequencies a subscriber can choose.
+const (
+	FrequencyDaily  = "daily"
+	FrequencyWeekly = "weekly"
+)
+
+// ConfirmResendInterval is how long a pending confirmation is kept before another subscription
+// request for the address mails a new confirmation link.
+const ConfirmResendInterval = 24 * time.Hour
+
+// ErrInvalidToken is returned for an unknown or already used confirmation or unsubscribe token.
+var ErrInvalidToken = errors.New("invalid or expired link")
+
+// Subscriber is an email digest subscription. A subscription only gets digests once the address
+// is confirmed (double opt-in) and until it is unsubscribed through its unsubscribe token.
+type Subscriber struct {
+	gorm.Model
+	Email            string `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Frequency        string `gorm:"type:varchar(20);not null"`
+	ConfirmToken     string `gorm:"type:varchar(64);index"`
+	UnsubscribeToken string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ConfirmedAt      *time.Time
+	UnsubscribedAt   *time.Time
+	LastSentAt       *time.Time
+}
+
+// Active reports whether the subscriber gets digests.
+func (s *Subscriber) Active() bool {
+	return s.ConfirmedAt != nil && s.UnsubscribedAt == nil
+}
+
+// newToken returns a random token for confirmation and unsubscribe links.
+func newToken() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Subscribe records a subscription request and returns the subscriber and whether a confirmation
+// email is due. A new or inactive subscriber gets a new confirmation token and the requested
+// frequency; an active subscriber is returned unchanged, so a subscription can not be changed
+// without its owner's links, and so is a confirmation pending for less than ConfirmResendInterval,
+// so repeated requests do not mail the address again.
+func Subscribe(db *gorm.DB, email, frequency string) (*Subscriber, bool, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	var subscriber Subscriber
+	err := db.Where("email = ?", email).First(&subscriber).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+	if subscriber.ID != 0 && subscriber.Active() {
+		return &subscriber, false, nil
+	}
+	if subscriber.ID != 0 && subscriber.ConfirmToken != "" && subscriber.UnsubscribedAt == nil &&
+		time.Since(subscriber.UpdatedAt) < ConfirmResendInterval {
+		return &subscriber, false, nil
+	}
+
+	confirmToken, err := newToken()
+	if err != nil {
+		return nil, false, err
+	}
+	subscriber.Email = email
+	subscriber.Frequency = frequency
+	subscriber.ConfirmToken = confirmToken
+	subscriber.ConfirmedAt = nil
+	subscriber.UnsubscribedAt = nil
+	if subscriber.UnsubscribeToken == "" {
+		subscriber.UnsubscribeToken, err = newToken()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	err = db.Save(&subscriber).Error
+	if err != nil {
+		return nil, false, err
+	}
+	return &subscriber, true, nil
+}
+
+// ConfirmSubscriber confirms the subscription of a confirmation token. Each token works once.
+func ConfirmSubscriber(db *gorm.DB, token string) (*Subscriber, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	var subscriber Subscriber
+	err := db.Where("confirm_token = ?", token).First(&subscriber).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	err = db.Model(&subscriber).Updates(map[string]interface{}{
+		"confirm_token": "",
+		"confirmed_at":  now,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	subscriber.ConfirmToken = ""
+	subscriber.ConfirmedAt = &now
+	return &subscriber, nil
+}
+
+// Unsubscribe ends the subscription of an unsubscribe token. Unsubscribing twice is not an error.
+func Unsubscribe(db *gorm.DB, token string) (*Subscriber, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+	var subscriber Subscriber
+	err := db.Where("unsubscribe_token = ?", token).First(&subscriber).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subscriber.UnsubscribedAt != nil {
+		return &subscriber, nil
+	}
+
+	err = db.Model(&subscriber).Update("unsubscribed_at", time.Now()).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscriber, nil
+}
+
+// DueSubscribers returns the active subscribers of a frequency that have not been sent a digest since the given time.
+func DueSubscribers(db *gorm.DB, frequency string, since time.Time) ([]Subscriber, error) {
+	var subscribers []Subscriber
+	err := db.Where("frequency = ? AND confirmed_at IS NOT NULL AND unsubscribed_at IS NULL", frequency).
+		Where("last_sent_at IS NULL OR last_sent_at < ?", since).
+		Order("id ASC").
+		Find(&subscribers).Error
+	return subscribers, err
+}
+
+// MarkSent records that a digest was sent to the subscriber.
+func (s *Subscriber) MarkSent(db *gorm.DB, at time.Time) error {
+	s.LastSentAt = &at
+	return db.Model(s).Update("last_sent_at", at).Error
+}
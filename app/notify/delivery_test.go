@@ -0,0 +1,91 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/dariubs/huntline/app/db"
+	"github.com/dariubs/huntline/app/migrations"
+	"gorm.io/gorm"
+)
+
+// testDB opens a new SQLite database migrated to the latest version.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb, err := db.Open(db.Config{Driver: db.DriverSQLite, Path: filepath.Join(t.TempDir(), "huntline.db")})
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	m, err := migrations.New(sqlDB, migrations.SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Up(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+	return gdb
+}
+
+func TestNotifySendsEachDateOnce(t *testing.T) {
+	requests := sinkServer(t, http.StatusOK)
+	gdb := testDB(t)
+	n, err := New(gdb, Config{Sinks: []SinkConfig{
+		{Name: "final", Type: "slack", URL: "https://hooks.slack.com/final"},
+		{Name: "fetch", Type: "slack", URL: "https://hooks.slack.com/fetch", On: OnFetch},
+	}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, step := range []struct {
+		final bool
+		want  []string
+	}{
+		{false, []string{"fetch"}},
+		{false, nil},
+		{true, []string{"final"}},
+		{true, nil},
+	} {
+		sent, err := n.Notify(ctx, testDigest(step.final))
+		if err != nil {
+			t.Fatalf("Notify: %v", err)
+		}
+		if len(sent) != len(step.want) || (len(sent) > 0 && sent[0] != step.want[0]) {
+			t.Errorf("final %v sent to %v, want %v", step.final, sent, step.want)
+		}
+	}
+	if len(*requests) != 2 {
+		t.Errorf("posted %d messages, want 2", len(*requests))
+	}
+}
+
+func TestNotifyRetriesFailedSend(t *testing.T) {
+	sinkServer(t, http.StatusInternalServerError)
+	gdb := testDB(t)
+	n, err := New(gdb, Config{Sinks: []SinkConfig{{Type: "slack", URL: "https://hooks.slack.com/x"}}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx := context.Background()
+
+	sent, err := n.Notify(ctx, testDigest(true))
+	if err == nil || len(sent) != 0 {
+		t.Fatalf("Notify to a failing sink sent to %v with error %v", sent, err)
+	}
+
+	// The failed send released its claim, so the next fetch posts the date
+	requests := sinkServer(t, http.StatusOK)
+	sent, err = n.Notify(ctx, testDigest(true))
+	if err != nil || len(sent) != 1 || len(*requests) != 1 {
+		t.Errorf("retry sent to %v with error %v, want the slack sink", sent, err)
+	}
+}
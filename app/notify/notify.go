@@ -0,0 +1,228 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+	"gorm.io/gorm"
+)
+
+// DefaultTemplate renders a digest as a ranked list, one product per line.
+const DefaultTemplate = `Top {{len .Products}} on {{.Platform}} for {{.Date.Format "Jan 2, 2006"}}{{if not .Final}} (provisional){{end}}
+{{range .Products}}
+{{.Rank}}. {{.Name}}{{if .Tagline}}: {{.Tagline}}{{end}}{{if .URL}}
+{{.URL}}{{end}}
+{{end}}`
+
+// When a sink posts a platform's top list of a date.
+const (
+	// OnFinal posts once the rankings of the date are final (the default)
+	OnFinal = "final"
+	// OnFetch posts after the first successful fetch of the date
+	OnFetch = "fetch"
+)
+
+// Digest is a platform's top list of a date, as passed to the message templates.
+type Digest struct {
+	Platform string
+	Date     time.Time
+	Final    bool
+	Products []model.Product
+}
+
+// SinkConfig configures one sink of the notifier config file.
+type SinkConfig struct {
+	// Name identifies the sink in logs and de-duplication records
+	Name string `json:"name"`
+	// Type is slack, discord or telegram
+	Type string `json:"type"`
+	// URL is the webhook of slack and discord sinks
+	URL string `json:"url"`
+	// Token and ChatID address the chat of telegram sinks
+	Token  string `json:"token"`
+	ChatID string `json:"chat_id"`
+	// Platforms routes only these platforms to the sink; empty routes every platform
+	Platforms []string `json:"platforms"`
+	// On is final (default) or fetch
+	On string `json:"on"`
+	// Template overrides the config's message template for this sink
+	Template string `json:"template"`
+}
+
+// Config is the notifier config file.
+type Config struct {
+	// Template is the text/template of messages, executed with a Digest (default DefaultTemplate)
+	Template string       `json:"template"`
+	Sinks    []SinkConfig `json:"sinks"`
+}
+
+// route is a configured sink with its platforms, trigger and template.
+type route struct {
+	name      string
+	sink      Sink
+	platforms map[string]bool
+	on        string
+	tmpl      *template.Template
+}
+
+// due reports whether the route's sink gets the digest: its platform is routed to the sink and the
+// rankings are final, unless the sink posts on fetch.
+func (r route) due(digest Digest) bool {
+	if r.platforms != nil && !r.platforms[digest.Platform] {
+		return false
+	}
+	return r.on == OnFetch || digest.Final
+}
+
+// Notifier posts the top list of a platform's date to the sinks routed to the platform.
+// Each sink gets each date once, however often the date is re-fetched.
+type Notifier struct {
+	db     *gorm.DB
+	routes []route
+}
+
+// Load reads a notifier config file and builds its sinks.
+func Load(db *gorm.DB, path string) (*Notifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	err = json.Unmarshal(data, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return New(db, cfg)
+}
+
+// New builds a notifier from its config.
+func New(db *gorm.DB, cfg Config) (*Notifier, error) {
+	if cfg.Template == "" {
+		cfg.Template = DefaultTemplate
+	}
+
+	n := &Notifier{db: db}
+	names := make(map[string]bool)
+	for i, sc := range cfg.Sinks {
+		if sc.Name == "" {
+			sc.Name = sc.Type
+		}
+		if names[sc.Name] {
+			return nil, fmt.Errorf("sink %d: duplicate name %q", i+1, sc.Name)
+		}
+		names[sc.Name] = true
+
+		sink, err := newSink(sc)
+		if err != nil {
+			return nil, fmt.Errorf("sink %q: %w", sc.Name, err)
+		}
+
+		on := sc.On
+		if on == "" {
+			on = OnFinal
+		}
+		if on != OnFinal && on != OnFetch {
+			return nil, fmt.Errorf("sink %q: invalid on %q, expected final or fetch", sc.Name, sc.On)
+		}
+
+		text := sc.Template
+		if text == "" {
+			text = cfg.Template
+		}
+		tmpl, err := template.New(sc.Name).Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("sink %q: parsing template: %w", sc.Name, err)
+		}
+
+		var platforms map[string]bool
+		if len(sc.Platforms) > 0 {
+			platforms = make(map[string]bool, len(sc.Platforms))
+			for _, p := range sc.Platforms {
+				platforms[p] = true
+			}
+		}
+
+		n.routes = append(n.routes, route{name: sc.Name, sink: sink, platforms: platforms, on: on, tmpl: tmpl})
+	}
+	return n, nil
+}
+
+// newSink creates the sink of a sink config.
+func newSink(sc SinkConfig) (Sink, error) {
+	switch strings.ToLower(sc.Type) {
+	case "slack":
+		if sc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		return SlackSink{URL: sc.URL}, nil
+	case "discord":
+		if sc.URL == "" {
+			return nil, errors.New("url is required")
+		}
+		return DiscordSink{URL: sc.URL}, nil
+	case "telegram":
+		if sc.Token == "" || sc.ChatID == "" {
+			return nil, errors.New("token and chat_id are required")
+		}
+		return TelegramSink{Token: sc.Token, ChatID: sc.ChatID}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q, expected slack, discord or telegram", sc.Type)
+}
+
+// Notify posts the digest to every sink routed to its platform and due for it, unless the sink
+// already got the date. It returns the names of the sinks posted to; failed sinks are retried on
+// the next call for the date.
+func (n *Notifier) Notify(ctx context.Context, digest Digest) ([]string, error) {
+	var sent []string
+	var errs []error
+	for _, r := range n.routes {
+		if !r.due(digest) {
+			continue
+		}
+
+		err := n.send(ctx, r, digest)
+		if errors.Is(err, errAlreadySent) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("sink %s: %w", r.name, err))
+			continue
+		}
+		sent = append(sent, r.name)
+	}
+	return sent, errors.Join(errs...)
+}
+
+var errAlreadySent = errors.New("already sent")
+
+// send claims the sink's notification of the date, then renders and posts the message.
+// The claim is released if posting fails.
+func (n *Notifier) send(ctx context.Context, r route, digest Digest) error {
+	var text strings.Builder
+	err := r.tmpl.Execute(&text, digest)
+	if err != nil {
+		return fmt.Errorf("rendering template: %w", err)
+	}
+
+	claimed, err := model.ClaimNotification(n.db.WithContext(ctx), r.name, digest.Platform, digest.Date)
+	if err != nil {
+		return fmt.Errorf("recording notification: %w", err)
+	}
+	if !claimed {
+		return errAlreadySent
+	}
+
+	err = r.sink.Send(ctx, strings.TrimSpace(text.String()))
+	if err != nil {
+		releaseErr := model.ReleaseNotification(n.db.WithContext(ctx), r.name, digest.Platform, digest.Date)
+		return errors.Join(err, releaseErr)
+	}
+	return nil
+}
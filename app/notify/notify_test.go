@@ -0,0 +1,121 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dariubs/huntline/app/model"
+)
+
+// testDigest is a top list of three ProductHunt products.
+func testDigest(final bool) Digest {
+	return Digest{
+		Platform: "producthunt",
+		Date:     time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+		Final:    final,
+		Products: []model.Product{
+			{Name: "Alpha", Tagline: "The first", URL: "https://alpha.example.com/", Rank: 1},
+			{Name: "Beta", Rank: 2},
+			{Name: "Gamma", Tagline: "The third", Rank: 3},
+		},
+	}
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	n, err := New(nil, Config{Sinks: []SinkConfig{{Type: "slack", URL: "https://hooks.slack.com/x"}}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for final, want := range map[bool]string{
+		true: `Top 3 on producthunt for Jan 15, 2025
+
+1. Alpha: The first
+https://alpha.example.com/
+
+2. Beta
+
+3. Gamma: The third`,
+		false: `Top 3 on producthunt for Jan 15, 2025 (provisional)
+
+1. Alpha: The first
+https://alpha.example.com/
+
+2. Beta
+
+3. Gamma: The third`,
+	} {
+		var text strings.Builder
+		err := n.routes[0].tmpl.Execute(&text, testDigest(final))
+		if err != nil {
+			t.Fatalf("rendering: %v", err)
+		}
+		if got := strings.TrimSpace(text.String()); got != want {
+			t.Errorf("final %v rendered\n%s\nwant\n%s", final, got, want)
+		}
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	n, err := New(nil, Config{
+		Template: "{{.Platform}}",
+		Sinks: []SinkConfig{
+			{Name: "everything", Type: "slack", URL: "https://hooks.slack.com/a"},
+			{Name: "producthunt-fetch", Type: "discord", URL: "https://discord.com/b", Platforms: []string{"producthunt"}, On: OnFetch},
+			{Name: "betalist", Type: "telegram", Token: "1:x", ChatID: "2", Platforms: []string{"betalist"}, Template: "{{.Date}}"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		platform string
+		final    bool
+		want     []string
+	}{
+		{"producthunt", false, []string{"producthunt-fetch"}},
+		{"producthunt", true, []string{"everything", "producthunt-fetch"}},
+		{"betalist", false, nil},
+		{"betalist", true, []string{"everything", "betalist"}},
+	}
+	for _, tt := range tests {
+		digest := testDigest(tt.final)
+		digest.Platform = tt.platform
+		var got []string
+		for _, r := range n.routes {
+			if r.due(digest) {
+				got = append(got, r.name)
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s final %v goes to %v, want %v", tt.platform, tt.final, got, tt.want)
+		}
+	}
+
+	// A sink's own template overrides the config's
+	var text strings.Builder
+	n.routes[2].tmpl.Execute(&text, testDigest(true))
+	if !strings.HasPrefix(text.String(), "2025-01-15") {
+		t.Errorf("betalist sink rendered %q with the config template", text.String())
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		sinks []SinkConfig
+	}{
+		{"unknown type", []SinkConfig{{Type: "irc"}}},
+		{"slack without url", []SinkConfig{{Type: "slack"}}},
+		{"telegram without chat", []SinkConfig{{Type: "telegram", Token: "1:x"}}},
+		{"duplicate name", []SinkConfig{{Type: "slack", URL: "https://a"}, {Type: "slack", URL: "https://b"}}},
+		{"invalid on", []SinkConfig{{Type: "slack", URL: "https://a", On: "daily"}}},
+		{"invalid template", []SinkConfig{{Type: "slack", URL: "https://a", Template: "{{.Name"}}},
+	}
+	for _, tt := range tests {
+		if _, err := New(nil, Config{Sinks: tt.sinks}); err == nil {
+			t.Errorf("%s: New succeeded, want an error", tt.name)
+		}
+	}
+}
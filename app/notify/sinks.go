@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Sink posts a rendered message to a chat service.
+type Sink interface {
+	Send(ctx context.Context, text string) error
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// postJSON posts payload as JSON to endpoint and fails on a non-2xx response.
+func postJSON(ctx context.Context, endpoint string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return errors.New("invalid sink URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// Webhook URLs and bot tokens are secrets, keep them out of the error
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("posting to %s: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	return nil
+}
+
+// truncate shortens text to at most max runes, for services that reject longer messages.
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+// SlackSink posts to a Slack incoming webhook, or any webhook accepting Slack's {"text": ...} payload.
+type SlackSink struct {
+	URL string
+}
+
+func (s SlackSink) Send(ctx context.Context, text string) error {
+	return postJSON(ctx, s.URL, map[string]string{"text": text})
+}
+
+// DiscordSink posts to a Discord webhook. Discord rejects messages over 2000 characters.
+type DiscordSink struct {
+	URL string
+}
+
+func (s DiscordSink) Send(ctx context.Context, text string) error {
+	return postJSON(ctx, s.URL, map[string]string{"content": truncate(text, 2000)})
+}
+
+// TelegramSink sends messages to a chat through a Telegram bot. Telegram rejects messages over 4096 characters.
+type TelegramSink struct {
+	Token  string
+	ChatID string
+}
+
+func (s TelegramSink) Send(ctx context.Context, text string) error {
+	return postJSON(ctx, "https://api.telegram.org/bot"+s.Token+"/sendMessage", map[string]interface{}{
+		"chat_id":                  s.ChatID,
+		"text":                     truncate(text, 4096),
+		"disable_web_page_preview": true,
+	})
+}
@@ -0,0 +1,116 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// request is a message received by sinkServer.
+type request struct {
+	Path string
+	Body map[string]interface{}
+}
+
+// sinkServer stands in for the chat services: every request, whatever host it was sent to, is
+// served by a test server answering with status and recorded.
+func sinkServer(t *testing.T, status int) *[]request {
+	t.Helper()
+	var requests []request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var body map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		requests = append(requests, request{Path: r.URL.Path, Body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	target, _ := url.Parse(server.URL)
+	saved := httpClient
+	httpClient = &http.Client{Transport: rewriteTransport{target: target}}
+	t.Cleanup(func() { httpClient = saved })
+	return &requests
+}
+
+// rewriteTransport sends every request to target.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (t rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestSinks(t *testing.T) {
+	long := strings.Repeat("é", 5000)
+	tests := []struct {
+		name     string
+		sink     Sink
+		text     string
+		wantPath string
+		wantBody map[string]interface{}
+	}{
+		{"slack", SlackSink{URL: "https://hooks.slack.com/services/T0/B0/secret"}, "Top 3",
+			"/services/T0/B0/secret", map[string]interface{}{"text": "Top 3"}},
+		{"discord", DiscordSink{URL: "https://discord.com/api/webhooks/1/secret"}, "Top 3",
+			"/api/webhooks/1/secret", map[string]interface{}{"content": "Top 3"}},
+		{"discord truncates", DiscordSink{URL: "https://discord.com/api/webhooks/1/secret"}, long,
+			"/api/webhooks/1/secret", map[string]interface{}{"content": strings.Repeat("é", 1999) + "…"}},
+		{"telegram", TelegramSink{Token: "123:secret", ChatID: "-100"}, "Top 3",
+			"/bot123:secret/sendMessage",
+			map[string]interface{}{"chat_id": "-100", "text": "Top 3", "disable_web_page_preview": true}},
+		{"telegram truncates", TelegramSink{Token: "123:secret", ChatID: "-100"}, long,
+			"/bot123:secret/sendMessage",
+			map[string]interface{}{"chat_id": "-100", "text": strings.Repeat("é", 4095) + "…", "disable_web_page_preview": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := sinkServer(t, http.StatusOK)
+			err := tt.sink.Send(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Send: %v", err)
+			}
+			if len(*requests) != 1 {
+				t.Fatalf("sent %d requests, want 1", len(*requests))
+			}
+			got := (*requests)[0]
+			if got.Path != tt.wantPath {
+				t.Errorf("path = %q, want %q", got.Path, tt.wantPath)
+			}
+			gotBody, _ := json.Marshal(got.Body)
+			wantBody, _ := json.Marshal(tt.wantBody)
+			if string(gotBody) != string(wantBody) {
+				t.Errorf("body = %.200s, want %.200s", gotBody, wantBody)
+			}
+		})
+	}
+}
+
+func TestSinkErrorHidesSecrets(t *testing.T) {
+	sinkServer(t, http.StatusForbidden)
+	for _, sink := range []Sink{
+		SlackSink{URL: "https://hooks.slack.com/services/T0/B0/secret"},
+		TelegramSink{Token: "123:secret", ChatID: "-100"},
+	} {
+		err := sink.Send(context.Background(), "Top 3")
+		if err == nil {
+			t.Fatalf("%T: Send succeeded on 403", sink)
+		}
+		if !strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "secret") {
+			t.Errorf("%T: error %q, want the status without the secret", sink, err)
+		}
+	}
+}
@@ -1,14 +1,47 @@
 package platform
 
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// DefaultDepth is the number of top products fetched per day when a platform has no depth configured
+const DefaultDepth = 10
+
+// depthEnv names the environment variable holding each platform's top-N depth
+var depthEnv = map[string]string{
+	"producthunt": "PH_TOP_N",
+}
+
+// Depth returns the configured top-N depth of a platform, or DefaultDepth when it is unset
+func Depth(name string) (int, error) {
+	key, ok := depthEnv[name]
+	if !ok {
+		return DefaultDepth, nil
+	}
+	value := os.Getenv(key)
+	if value == "" {
+		return DefaultDepth, nil
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth <= 0 {
+		return 0, fmt.Errorf("invalid %s: expected a positive number, got %q", key, value)
+	}
+	return depth, nil
+}
+
 // LaunchPlatform defines the interface that all launch platforms must implement
 type LaunchPlatform interface {
 	// GetName returns the name/identifier of the platform (e.g., "producthunt", "altern")
 	GetName() string
 	
 	// GetTopProducts fetches the top products for a given date
+	// ctx: cancels the fetch and carries the span its requests are traced under
 	// date: date in format YYYY-MM-DD
 	// limit: maximum number of products to fetch
 	// Returns a slice of Product structs
-	GetTopProducts(date string, limit int) ([]Product, error)
+	GetTopProducts(ctx context.Context, date string, limit int) ([]Product, error)
 }
 
@@ -1,21 +1,63 @@
 package producthunt
 
 import (
-	"github.com/dariubs/go-producthunt"
-	"github.com/dariubs/huntline/app/platform"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
 	"time"
+
+	"github.com/dariubs/huntline/app/platform"
+	"github.com/dariubs/huntline/app/tracing"
 )
 
 const PlatformName = "producthunt"
 
+// GraphQLURL is the endpoint of the ProductHunt API v2
+const GraphQLURL = "https://api.producthunt.com/v2/api/graphql"
+
+// rankingQuery returns a page of the top posts of a day in ranking order, after the cursor of the
+// previous page
+const rankingQuery = `query($postedAfter: DateTime, $postedBefore: DateTime, $first: Int, $after: String) {
+  posts(order: RANKING, postedAfter: $postedAfter, postedBefore: $postedBefore, first: $first, after: $after) {
+    edges {
+      node {
+        id
+        name
+        tagline
+        website
+        thumbnail {
+          url
+        }
+      }
+    }
+    pageInfo {
+      endCursor
+      hasNextPage
+    }
+  }
+}`
+
+// pageSize is the number of posts requested per page; the API returns at most 20
+const pageSize = 20
+
 type ProductHuntPlatform struct {
-	client producthunt.ProductHunt
+	apiKey string
+	// URL is the GraphQL endpoint, GraphQLURL unless pointed at a stand-in
+	URL    string
+	client *http.Client
 }
 
 // NewProductHuntPlatform creates a new ProductHunt platform instance
 func NewProductHuntPlatform(apiKey string) *ProductHuntPlatform {
 	return &ProductHuntPlatform{
-		client: producthunt.ProductHunt{APIKey: apiKey},
+		apiKey: apiKey,
+		URL:    GraphQLURL,
+		// Requests are traced as children of the fetch span in their context
+		client: &http.Client{Timeout: 30 * time.Second, Transport: tracing.Transport(nil)},
 	}
 }
 
@@ -24,48 +66,95 @@ func (p *ProductHuntPlatform) GetName() string {
 	return PlatformName
 }
 
-// GetTopProducts fetches top products from ProductHunt for a given date
-func (p *ProductHuntPlatform) GetTopProducts(date string, limit int) ([]platform.Product, error) {
+// rankingResponse is the GraphQL response of rankingQuery.
+type rankingResponse struct {
+	Data struct {
+		Posts struct {
+			Edges    []rankingEdge `json:"edges"`
+			PageInfo struct {
+				EndCursor   string `json:"endCursor"`
+				HasNextPage bool   `json:"hasNextPage"`
+			} `json:"pageInfo"`
+		} `json:"posts"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// rankingEdge is a post of rankingResponse.
+type rankingEdge struct {
+	Node struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Tagline string `json:"tagline"`
+		Website string `json:"website"`
+		// Thumbnail is the logo of the launch, the last resort of the logo proxy
+		Thumbnail struct {
+			URL string `json:"url"`
+		} `json:"thumbnail"`
+	} `json:"node"`
+}
+
+// GetTopProducts fetches the top limit products from ProductHunt for a given date, a page at a
+// time
+func (p *ProductHuntPlatform) GetTopProducts(ctx context.Context, date string, limit int) ([]platform.Product, error) {
 	// Use San Francisco timezone (Pacific Time)
 	loc, err := time.LoadLocation("America/Los_Angeles")
 	if err != nil {
 		return nil, err
 	}
-	
+
 	parsedDate, err := time.ParseInLocation("2006-01-02", date, loc)
 	if err != nil {
 		return nil, err
 	}
 
-	phProducts, err := p.client.GetProductsByRankByDate(date)
-	if err != nil {
-		return nil, err
+	// Page through the ranking until limit posts are fetched; without a limit one page is enough
+	var edges []rankingEdge
+	after := ""
+	for {
+		first := pageSize
+		if limit > 0 {
+			first = min(pageSize, limit-len(edges))
+		}
+		variables := map[string]interface{}{
+			"postedAfter":  date + "T00:00:00Z",
+			"postedBefore": date + "T23:59:59Z",
+			"first":        first,
+		}
+		if after != "" {
+			variables["after"] = after
+		}
+		response, err := p.query(ctx, rankingQuery, variables)
+		if err != nil {
+			return nil, err
+		}
+		posts := response.Data.Posts
+		edges = append(edges, posts.Edges...)
+		if limit <= 0 || len(edges) >= limit || !posts.PageInfo.HasNextPage || posts.PageInfo.EndCursor == "" {
+			break
+		}
+		after = posts.PageInfo.EndCursor
 	}
 
 	// Limit the number of products if needed
-	if limit > 0 && limit < len(phProducts) {
-		phProducts = phProducts[:limit]
+	if limit > 0 && limit < len(edges) {
+		edges = edges[:limit]
 	}
 
 	// Ensure parsedDate is normalized to midnight in PST to avoid timezone conversion issues
 	// This ensures the date stays as the requested date when saved to the database
 	normalizedDate := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 0, 0, 0, 0, loc)
 
-	products := make([]platform.Product, len(phProducts))
-	for i, phProduct := range phProducts {
-		// Generate Google favicon URL from the product's website URL
-		// This ensures we get the actual product logo, not ProductHunt's thumbnail
-		logoURL := ""
-		if phProduct.Website != "" {
-			logoURL = "https://www.google.com/s2/favicons?domain=" + phProduct.Website + "&sz=64"
-		}
-
+	products := make([]platform.Product, len(edges))
+	for i, edge := range edges {
 		products[i] = platform.Product{
-			Name:        phProduct.Name,
-			Tagline:     phProduct.Tagline,
-			URL:         phProduct.Website,
+			Name:        edge.Node.Name,
+			Tagline:     edge.Node.Tagline,
+			URL:         edge.Node.Website,
 			Rank:        uint(i + 1),
-			Logo:        logoURL, // Use Google favicon service for correct product logos
+			Logo:        edge.Node.Thumbnail.URL,
 			Date:        normalizedDate, // Use normalized date to ensure correct date is saved
 			Platform:    PlatformName,
 			Description: "", // ProductHunt API doesn't provide description in the current struct
@@ -75,3 +164,43 @@ func (p *ProductHuntPlatform) GetTopProducts(date string, limit int) ([]platform
 	return products, nil
 }
 
+// query sends a GraphQL query and decodes its response, failing on GraphQL errors.
+func (p *ProductHuntPlatform) query(ctx context.Context, query string, variables map[string]interface{}) (*rankingResponse, error) {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("producthunt API returned %s", resp.Status)
+	}
+
+	var response rankingResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("invalid producthunt API response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		messages := make([]string, len(response.Errors))
+		for i, e := range response.Errors {
+			messages[i] = e.Message
+		}
+		return nil, fmt.Errorf("producthunt API error: %s", strings.Join(messages, "; "))
+	}
+	return &response, nil
+}
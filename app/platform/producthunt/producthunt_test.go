@@ -0,0 +1,89 @@
+package producthunt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// rankingServer stands in for the GraphQL API, serving total posts of a day in pages and
+// recording the page sizes requested.
+func rankingServer(t *testing.T, total int) (*httptest.Server, *[]int) {
+	t.Helper()
+	var pages []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var request struct {
+			Variables struct {
+				First int    `json:"first"`
+				After string `json:"after"`
+			} `json:"variables"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&request)
+		if err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+		pages = append(pages, request.Variables.First)
+
+		start := 0
+		if request.Variables.After != "" {
+			start, _ = strconv.Atoi(request.Variables.After)
+		}
+		end := min(start+request.Variables.First, total)
+		var response rankingResponse
+		for i := start; i < end; i++ {
+			var edge rankingEdge
+			edge.Node.Name = fmt.Sprintf("Product %d", i+1)
+			edge.Node.Website = fmt.Sprintf("https://www.producthunt.com/r/%d", i+1)
+			response.Data.Posts.Edges = append(response.Data.Posts.Edges, edge)
+		}
+		response.Data.Posts.PageInfo.EndCursor = strconv.Itoa(end)
+		response.Data.Posts.PageInfo.HasNextPage = end < total
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+	return server, &pages
+}
+
+func TestGetTopProductsPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		total     int
+		limit     int
+		wantCount int
+		wantPages []int
+	}{
+		{"one page", 100, 10, 10, []int{10}},
+		{"several pages", 100, 50, 50, []int{20, 20, 10}},
+		{"short day", 30, 50, 30, []int{20, 20}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, pages := rankingServer(t, tt.total)
+			p := NewProductHuntPlatform("key")
+			p.URL = server.URL
+
+			products, err := p.GetTopProducts(context.Background(), "2025-01-15", tt.limit)
+			if err != nil {
+				t.Fatalf("GetTopProducts: %v", err)
+			}
+			if len(products) != tt.wantCount {
+				t.Fatalf("got %d products, want %d", len(products), tt.wantCount)
+			}
+			for i, product := range products {
+				if product.Rank != uint(i+1) || product.Name != fmt.Sprintf("Product %d", i+1) {
+					t.Errorf("product %d is %s at rank %d", i, product.Name, product.Rank)
+				}
+			}
+			if fmt.Sprint(*pages) != fmt.Sprint(tt.wantPages) {
+				t.Errorf("requested pages of %v, want %v", *pages, tt.wantPages)
+			}
+		})
+	}
+}
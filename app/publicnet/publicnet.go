@@ -0,0 +1,93 @@
+// Package publicnet provides HTTP clients for fetching user-supplied URLs, such as product
+// websites, that only connect to public addresses. It keeps a link pointing at localhost, a
+// private network or a cloud metadata service from making HuntLine request it.
+package publicnet
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is the number of redirects a client follows, as many as http.DefaultClient.
+const maxRedirects = 10
+
+// Client returns a client giving up after timeout that only connects to public addresses, see
+// Transport, and checks every redirect with CheckRedirect.
+func Client(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: Transport(), CheckRedirect: CheckRedirect}
+}
+
+// Transport returns a transport that checks every address it connects to with Check. Proxies are
+// not used, so the check applies to the requested host itself.
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: 5 * time.Second, Control: control}).DialContext
+	return transport
+}
+
+// CheckRedirect is an http.Client CheckRedirect hook that stops after 10 redirects and refuses
+// redirects to other schemes than http and https or to a non-public IP address. Redirects to a
+// host name are checked again once it is resolved, when the transport connects.
+func CheckRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("refusing to follow a redirect to %s", req.URL.Scheme)
+	}
+	host := strings.ToLower(strings.TrimSuffix(req.URL.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("refusing to follow a redirect to %s", host)
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return Check(ip)
+	}
+	return nil
+}
+
+// blockedPrefixes are non-public ranges that netip.Addr has no predicate for.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// ErrNotPublic is returned for connections to a non-public address.
+var ErrNotPublic = errors.New("non-public address")
+
+// Check refuses loopback, private (RFC 1918 and unique local), link-local (including the
+// 169.254.169.254 metadata service) and other non-public addresses.
+func Check(ip netip.Addr) error {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+		return fmt.Errorf("refusing to connect to %w %s", ErrNotPublic, ip)
+	}
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("refusing to connect to %w %s", ErrNotPublic, ip)
+		}
+	}
+	return nil
+}
+
+// control is a net.Dialer Control hook applying Check to the address of every connection. It
+// runs after DNS resolution, so a public name resolving to a non-public address is refused too.
+func control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	return Check(ip)
+}
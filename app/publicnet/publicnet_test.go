@@ -0,0 +1,91 @@
+package publicnet
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestControl(t *testing.T) {
+	tests := []struct {
+		address string
+		allowed bool
+	}{
+		{"93.184.215.14:443", true},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", true},
+		{"127.0.0.1:80", false},
+		{"[::1]:80", false},
+		{"10.1.2.3:80", false},
+		{"172.16.0.1:80", false},
+		{"192.168.1.1:80", false},
+		{"169.254.169.254:80", false},
+		{"[fe80::1]:80", false},
+		{"[fd00::1]:80", false},
+		{"[::ffff:127.0.0.1]:80", false},
+		{"0.0.0.0:80", false},
+		{"100.64.0.1:80", false},
+	}
+	for _, tt := range tests {
+		err := control("tcp", tt.address, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("control(%s) = %v, want allowed %v", tt.address, err, tt.allowed)
+		}
+	}
+}
+
+func TestCheckRedirect(t *testing.T) {
+	tests := []struct {
+		target  string
+		allowed bool
+	}{
+		{"https://example.com/", true},
+		{"http://93.184.215.14/", true},
+		{"http://127.0.0.1/", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[::1]:8080/", false},
+		{"http://localhost/", false},
+		{"http://api.localhost./", false},
+		{"file:///etc/passwd", false},
+		{"ftp://example.com/", false},
+	}
+	for _, tt := range tests {
+		target, err := url.Parse(tt.target)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", tt.target, err)
+		}
+		err = CheckRedirect(&http.Request{URL: target}, nil)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckRedirect(%s) = %v, want allowed %v", tt.target, err, tt.allowed)
+		}
+	}
+
+	target, _ := url.Parse("https://example.com/")
+	if err := CheckRedirect(&http.Request{URL: target}, make([]*http.Request, maxRedirects)); err == nil {
+		t.Errorf("CheckRedirect after %d redirects succeeded, want an error", maxRedirects)
+	}
+}
+
+func TestClientRefusesLocalAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Client(time.Second).Do(req)
+	if !errors.Is(err, ErrNotPublic) {
+		t.Errorf("request to a loopback address = %v, want ErrNotPublic", err)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("loopback server got %d requests", n)
+	}
+}
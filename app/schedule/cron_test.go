@@ -0,0 +1,102 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	la, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Fatalf("loading timezone: %v", err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("parsing %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		// 2025-03-09 02:00 PST jumps to 03:00 PDT
+		{"hourly across spring forward", "0 * * * *", "2025-03-09T01:30:00-08:00", "2025-03-09T03:00:00-07:00"},
+		{"hourly after spring forward", "0 * * * *", "2025-03-09T03:00:00-07:00", "2025-03-09T04:00:00-07:00"},
+		{"skipped time runs at end of gap", "30 2 * * *", "2025-03-09T00:00:00-08:00", "2025-03-09T03:00:00-07:00"},
+		{"skipped time runs once", "30 2 * * *", "2025-03-09T03:00:00-07:00", "2025-03-10T02:30:00-07:00"},
+		{"skipped time before a run", "30 2,3 * * *", "2025-03-09T01:00:00-08:00", "2025-03-09T03:00:00-07:00"},
+		{"run after skipped time", "30 2,3 * * *", "2025-03-09T03:00:00-07:00", "2025-03-09T03:30:00-07:00"},
+		{"skipped time covered by a run", "0 2,3 * * *", "2025-03-09T01:00:00-08:00", "2025-03-09T03:00:00-07:00"},
+		{"covering run runs once", "0 2,3 * * *", "2025-03-09T03:00:00-07:00", "2025-03-10T02:00:00-07:00"},
+		{"local time kept over spring forward", "0 9 * * *", "2025-03-08T10:00:00-08:00", "2025-03-09T09:00:00-07:00"},
+		// 2025-11-02 02:00 PDT falls back to 01:00 PST
+		{"repeated time runs first", "30 1 * * *", "2025-11-02T00:00:00-07:00", "2025-11-02T01:30:00-07:00"},
+		{"repeated time runs once", "30 1 * * *", "2025-11-02T01:30:00-07:00", "2025-11-03T01:30:00-08:00"},
+		{"hourly across fall back", "0 * * * *", "2025-11-02T01:00:00-07:00", "2025-11-02T02:00:00-08:00"},
+		{"hourly inside repeated hour", "0 * * * *", "2025-11-02T01:30:00-08:00", "2025-11-02T02:00:00-08:00"},
+		{"local time kept over fall back", "0 9 * * *", "2025-11-01T10:00:00-07:00", "2025-11-02T09:00:00-08:00"},
+
+		{"list", "15,45 * * * *", "2025-01-15T10:20:00-08:00", "2025-01-15T10:45:00-08:00"},
+		{"range with step", "0 9-17/4 * * *", "2025-01-15T10:00:00-08:00", "2025-01-15T13:00:00-08:00"},
+		{"wildcard step", "*/20 * * * *", "2025-01-15T10:40:00-08:00", "2025-01-15T11:00:00-08:00"},
+		{"weekday range with step", "0 0 * * 1-5/2", "2025-01-01T00:00:00-08:00", "2025-01-03T00:00:00-08:00"},
+		{"sunday as 7", "0 0 * * 7", "2025-01-01T00:00:00-08:00", "2025-01-05T00:00:00-08:00"},
+		{"descriptor", "@monthly", "2025-01-15T00:00:00-08:00", "2025-02-01T00:00:00-08:00"},
+		{"timezone prefix", "CRON_TZ=UTC 0 12 * * *", "2025-01-15T00:00:00Z", "2025-01-15T12:00:00Z"},
+		{"leap day", "0 0 29 2 *", "2025-01-01T00:00:00-08:00", "2028-02-29T00:00:00-08:00"},
+
+		// day of month and day of week: either matches when both are restricted, both must otherwise
+		{"dom or dow, dow first", "0 0 13 * 5", "2025-01-01T00:00:00-08:00", "2025-01-03T00:00:00-08:00"},
+		{"dom or dow, dom first", "0 0 13 * 5", "2025-01-10T01:00:00-08:00", "2025-01-13T00:00:00-08:00"},
+		{"dow only", "0 0 * * 1", "2025-01-01T00:00:00-08:00", "2025-01-06T00:00:00-08:00"},
+		{"dom only", "0 0 1 * *", "2025-01-15T00:00:00-08:00", "2025-02-01T00:00:00-08:00"},
+		{"dom with dow step", "0 0 13 * */1", "2025-01-01T01:00:00-08:00", "2025-01-13T00:00:00-08:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Parse(tt.expr, la)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expr, err)
+			}
+			got := c.Next(at(tt.from))
+			if want := at(tt.want); !got.Equal(want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, want)
+			}
+		})
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	c, err := Parse("0 0 31 2 *", time.UTC)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got := c.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); !got.IsZero() {
+		t.Errorf("Next = %s, want the zero time", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"CRON_TZ=Nowhere/City * * * * *",
+		"CRON_TZ=UTC",
+	} {
+		if _, err := Parse(expr, time.UTC); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package schedule
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a named task run on a cron schedule.
+type Job struct {
+	Name string
+	Cron *Cron
+	Run  func()
+}
+
+// Scheduler runs jobs on their cron schedules, one job at a time.
+type Scheduler struct {
+	mu   sync.Mutex
+	jobs []*entry
+}
+
+// Upcoming is the next scheduled run of a job.
+type Upcoming struct {
+	Job  string    `json:"job"`
+	Cron string    `json:"cron"`
+	Next time.Time `json:"next"`
+}
+
+type entry struct {
+	Job
+	next time.Time
+}
+
+// New returns an empty scheduler.
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Add registers a job. Jobs due at the same time run in the order they were added.
+func (s *Scheduler) Add(job Job) {
+	s.jobs = append(s.jobs, &entry{Job: job})
+}
+
+// Run runs the jobs when they are due until ctx is cancelled. A job that is still
+// running when its next time comes skips that time rather than running twice.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	for _, e := range s.jobs {
+		e.next = e.Cron.Next(now)
+		slog.Info("Next run scheduled", "job", e.Name, "cron", e.Cron.String(), "next", e.next)
+	}
+	s.mu.Unlock()
+
+	for {
+		earliest := s.earliest()
+		if earliest.IsZero() {
+			slog.Info("No job has a future run, stopping scheduler")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(earliest))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, e := range s.jobs {
+			if e.next.IsZero() || e.next.After(time.Now()) {
+				continue
+			}
+			slog.Info("Running job", "job", e.Name)
+			e.Run()
+
+			s.mu.Lock()
+			e.next = e.Cron.Next(time.Now())
+			s.mu.Unlock()
+			slog.Info("Next run scheduled", "job", e.Name, "cron", e.Cron.String(), "next", e.next)
+		}
+	}
+}
+
+// earliest returns the time of the next due job, or the zero time if no job has a future run.
+func (s *Scheduler) earliest() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var earliest time.Time
+	for _, e := range s.jobs {
+		if !e.next.IsZero() && (earliest.IsZero() || e.next.Before(earliest)) {
+			earliest = e.next
+		}
+	}
+	return earliest
+}
+
+// Upcoming returns the next scheduled run of every job. It is empty until Run has started.
+func (s *Scheduler) Upcoming() []Upcoming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upcoming := make([]Upcoming, 0, len(s.jobs))
+	for _, e := range s.jobs {
+		if e.next.IsZero() {
+			continue
+		}
+		upcoming = append(upcoming, Upcoming{Job: e.Name, Cron: e.Cron.String(), Next: e.next})
+	}
+	return upcoming
+}
@@ -0,0 +1,258 @@
+// Package targeturl resolves the links launch platforms return to the product's own site
+// and canonicalizes them, so that the same site is stored under the same URL.
+package targeturl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dariubs/huntline/app/publicnet"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+// trackingParams are query parameters that only identify the referrer or campaign of a link.
+var trackingParams = map[string]bool{
+	"ref":     true,
+	"ref_src": true,
+	"ref_url": true,
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_hsenc":  true,
+	"_hsmi":   true,
+	"mkt_tok": true,
+}
+
+// isTrackingParam reports whether a query parameter only tracks the referrer or campaign of a link.
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return trackingParams[name] || strings.HasPrefix(name, "utm_")
+}
+
+// Canonicalize normalizes a URL: the scheme and host are lowercased, internationalized hosts are
+// converted to their ASCII (punycode) form, default ports, fragments and tracking parameters (ref,
+// utm_*, fbclid, ...) are removed, the remaining query parameters are sorted and an empty path
+// becomes "/". A URL without a scheme is taken as https.
+func Canonicalize(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("empty URL")
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return "", fmt.Errorf("URL %q has no host", raw)
+	}
+	// bücher.de and xn--bcher-kva.de are the same host
+	if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+		host = ascii
+	}
+	port := u.Port()
+	if (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		port = ""
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host += ":" + port
+	}
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if isTrackingParam(name) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	u.ForceQuery = false
+	return u.String(), nil
+}
+
+// Resolver follows the redirects of platform links to the URL they end up at.
+type Resolver struct {
+	// Client sends the requests; nil uses a client with a 10 second timeout that only connects to
+	// public addresses
+	Client *http.Client
+}
+
+// defaultClient follows up to 10 redirects, like http.DefaultClient, but gives up after 10 seconds.
+// It only connects to public addresses and checks every redirect, see publicnet.
+var defaultClient = publicnet.Client(10 * time.Second)
+
+// UserAgent identifies the requests HuntLine sends to product sites.
+const UserAgent = "HuntLine (+https://github.com/dariubs/huntline)"
+
+// Resolve returns the URL a link redirects to, or the link itself if it does not redirect.
+// The response body is not read.
+func (r Resolver) Resolve(ctx context.Context, raw string) (string, error) {
+	client := r.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, raw, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	return resp.Request.URL.String(), nil
+}
+
+// Canonical resolves a link and canonicalizes the URL it ends up at. If the link can not be
+// resolved, the link itself is canonicalized and the resolve error is returned alongside it.
+func (r Resolver) Canonical(ctx context.Context, raw string) (string, error) {
+	target, resolveErr := r.Resolve(ctx, raw)
+	if resolveErr != nil {
+		target = raw
+	}
+	canonical, err := Canonicalize(target)
+	if err != nil {
+		return "", err
+	}
+	if resolveErr != nil {
+		return canonical, fmt.Errorf("resolving %s: %w", raw, resolveErr)
+	}
+	return canonical, nil
+}
+
+// platformHosts are the hosts of launch platforms. A link still on them after resolving was not
+// resolved to the product's own site.
+var platformHosts = map[string]bool{
+	"producthunt.com":      true,
+	"betalist.com":         true,
+	"news.ycombinator.com": true,
+	"indiehackers.com":     true,
+	"devhunt.org":          true,
+}
+
+// OnPlatform reports whether a URL is on the host of a launch platform, e.g. a ProductHunt
+// redirect link that could not be resolved.
+func OnPlatform(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	return platformHosts[strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")]
+}
+
+// PlatformHosts returns the hosts of launch platforms, without www.
+func PlatformHosts() []string {
+	hosts := make([]string, 0, len(platformHosts))
+	for host := range platformHosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// sharedHosts host many products, so a product on them is identified by part of its path or query.
+var sharedHosts = map[string]func(u *url.URL) string{
+	"github.com":                   pathPrefix(2),
+	"gitlab.com":                   pathPrefix(2),
+	"bitbucket.org":                pathPrefix(2),
+	"apps.apple.com":               lastSegment,
+	"chrome.google.com":            lastSegment,
+	"chromewebstore.google.com":    lastSegment,
+	"addons.mozilla.org":           lastSegment,
+	"play.google.com":              queryParam("id"),
+	"marketplace.visualstudio.com": queryParam("itemName"),
+}
+
+// pathPrefix identifies a product by the first n segments of its path, e.g. owner/repository.
+func pathPrefix(n int) func(u *url.URL) string {
+	return func(u *url.URL) string {
+		segments := strings.FieldsFunc(strings.ToLower(u.Path), func(r rune) bool { return r == '/' })
+		if len(segments) > n {
+			segments = segments[:n]
+		}
+		return strings.Join(segments, "/")
+	}
+}
+
+// lastSegment identifies a product by the last segment of its path, e.g. an app store ID.
+func lastSegment(u *url.URL) string {
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return ""
+	}
+	return segments[len(segments)-1]
+}
+
+// queryParam identifies a product by a query parameter, e.g. a package name.
+func queryParam(name string) func(u *url.URL) string {
+	return func(u *url.URL) string {
+		return u.Query().Get(name)
+	}
+}
+
+// DomainKey returns the key identifying the product a canonical URL belongs to across platforms:
+// its host without www., followed by the part of the path or query that names the product on hosts
+// shared by many products, such as github.com/owner/repository. It returns "" for invalid URLs
+// and for URLs on a launch platform, which were not resolved to the product's own site.
+func DomainKey(canonical string) string {
+	u, err := url.Parse(canonical)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := trimWWW(strings.ToLower(u.Hostname()))
+	if platformHosts[host] {
+		return ""
+	}
+	if product, ok := sharedHosts[host]; ok {
+		if name := product(u); name != "" {
+			return host + "/" + name
+		}
+	}
+	return host
+}
+
+// trimWWW removes the www. label of a host, unless what is left is a public suffix such as co.uk.
+func trimWWW(host string) string {
+	rest, ok := strings.CutPrefix(host, "www.")
+	if !ok {
+		return host
+	}
+	if suffix, _ := publicsuffix.PublicSuffix(rest); suffix == rest {
+		return host
+	}
+	return rest
+}
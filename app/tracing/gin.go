@@ -0,0 +1,12 @@
+package tracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// GinMiddleware starts a server span for every request, continuing the trace of incoming
+// traceparent headers. Handlers pass c.Request.Context() to GORM to trace their queries.
+func GinMiddleware(service string) gin.HandlerFunc {
+	return otelgin.Middleware(service)
+}